@@ -23,12 +23,15 @@ func main() {
 
 	// use like this:
 	jsonRPC := spdk.NewClient(spdkAddress)
-	version := jsonRPC.GetVersion(ctx)
+	version, err := jsonRPC.GetVersion(ctx)
+	if err != nil {
+		log.Fatalf("failed to get SPDK version: %v", err)
+	}
 	log.Printf("Received from SPDK: %v", version)
 
 	// or like this:
-	var ver spdk.GetVersionResult
-	err := jsonRPC.Call(ctx, "spdk_get_version", nil, &ver)
+	var ver spdk.SpdkVersion
+	err = jsonRPC.Call(ctx, "spdk_get_version", nil, &ver)
 	if err != nil {
 		log.Fatalf("failed to get SPDK version: %v", err)
 	}
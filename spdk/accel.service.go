@@ -14,4 +14,6 @@ type AccelService interface {
 	CryptoKeyDestroy(context.Context, *AccelCryptoKeyDestroyParams) (*AccelCryptoKeyDestroyResult, error)
 	CryptoKeyList(context.Context, *AccelCryptoKeyGetParams) (*AccelCryptoKeyGetResult, error)
 	GetStats(context.Context, *NvmfCreateSubsystemParams) (*NvmfCreateSubsystemResult, error)
+	GetAccelEngineInfo(ctx context.Context) (AccelInfo, error)
+	SetAccelOpcAssignment(ctx context.Context, opcode, module string) error
 }
@@ -7,8 +7,12 @@ package spdk
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // AccelServiceImpl implements AccelService interface
@@ -20,9 +24,8 @@ type AccelServiceImpl struct {
 var _ AccelService = (*AccelServiceImpl)(nil)
 
 // NewAccelService is a constructor for AccelServiceImpl
-func NewAccelService() *AccelServiceImpl {
-	// client := spdk.NewClient(spdkAddress)
-	return &AccelServiceImpl{nil}
+func NewAccelService(client JSONRPC) *AccelServiceImpl {
+	return &AccelServiceImpl{client}
 }
 
 // CryptoKeyCreate creates crypto key
@@ -67,3 +70,51 @@ func (p *AccelServiceImpl) GetStats(_ context.Context, _ *NvmfCreateSubsystemPar
 	// TBD
 	return nil, nil
 }
+
+// GetAccelEngineInfo gets the module, e.g. software, dsa or iaa, each
+// operation is assigned to, along with the operations every loaded module
+// supports. SPDK versions without accel_get_module_info only report the
+// assignments.
+func (p *AccelServiceImpl) GetAccelEngineInfo(ctx context.Context) (AccelInfo, error) {
+	var info AccelInfo
+	err := p.client.Call(ctx, "accel_get_opc_assignments", nil, &info.Assignments)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return AccelInfo{}, err
+	}
+	err = p.client.Call(ctx, "accel_get_module_info", nil, &info.Modules)
+	if err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == MethodNotFoundCode {
+			return info, nil
+		}
+		log.Printf("error: %v", err)
+		return AccelInfo{}, err
+	}
+	return info, nil
+}
+
+// SetAccelOpcAssignment assigns the operation, e.g. crc32c, to the module,
+// e.g. dsa. SPDK only accepts this before framework_start_init, so the target
+// has to be started with --wait-for-rpc.
+func (p *AccelServiceImpl) SetAccelOpcAssignment(ctx context.Context, opcode, module string) error {
+	if opcode == "" || module == "" {
+		return status.Error(codes.InvalidArgument, "missing opcode or module to assign")
+	}
+	params := AccelAssignOpcParams{
+		Opname: opcode,
+		Module: module,
+	}
+	var result AccelAssignOpcResult
+	err := p.client.Call(ctx, "accel_assign_opc", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not assign %s to accel module: %s", opcode, module)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
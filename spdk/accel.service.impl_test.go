@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAccelService_GetAccelEngineInfo(t *testing.T) {
+	assignments := `{"copy":"software","crc32c":"dsa","compress":"iaa"}`
+	failure := &RPCError{Code: InternalErrorCode, Message: "Internal error"}
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    AccelInfo
+		wantErr error
+	}{
+		"assignments and modules": {
+			NewMockJSONRPC().
+				On("accel_get_opc_assignments", assignments).
+				On("accel_get_module_info", `[{"module":"software","supported ops":["copy","crc32c"]},{"module":"dsa","supported ops":["crc32c"]}]`),
+			AccelInfo{
+				Assignments: map[string]string{"copy": "software", "crc32c": "dsa", "compress": "iaa"},
+				Modules: []AccelModuleInfo{
+					{Module: "software", SupportedOps: []string{"copy", "crc32c"}},
+					{Module: "dsa", SupportedOps: []string{"crc32c"}},
+				},
+			},
+			nil,
+		},
+		"without module info": {
+			NewMockJSONRPC().On("accel_get_opc_assignments", assignments),
+			AccelInfo{Assignments: map[string]string{"copy": "software", "crc32c": "dsa", "compress": "iaa"}},
+			nil,
+		},
+		"assignments failure": {
+			NewMockJSONRPC().OnError("accel_get_opc_assignments", failure),
+			AccelInfo{},
+			failure,
+		},
+		"module info failure": {
+			NewMockJSONRPC().On("accel_get_opc_assignments", assignments).OnError("accel_get_module_info", failure),
+			AccelInfo{},
+			failure,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewAccelService(tt.mock).GetAccelEngineInfo(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestAccelService_SetAccelOpcAssignment(t *testing.T) {
+	tests := map[string]struct {
+		opcode   string
+		module   string
+		mock     *MockJSONRPC
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"assigned": {
+			"crc32c", "dsa",
+			NewMockJSONRPC().On("accel_assign_opc", true),
+			codes.OK,
+			nil,
+		},
+		"unexpected result": {
+			"crc32c", "dsa",
+			NewMockJSONRPC().On("accel_assign_opc", false),
+			codes.FailedPrecondition,
+			ErrUnexpectedSpdkCallResult,
+		},
+		"missing module": {
+			"crc32c", "",
+			NewMockJSONRPC(),
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewAccelService(tt.mock).SetAccelOpcAssignment(context.Background(), tt.opcode, tt.module)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			var want []MockCall
+			if tt.wantCode != codes.InvalidArgument {
+				want = []MockCall{{Method: "accel_assign_opc", Args: &AccelAssignOpcParams{Opname: tt.opcode, Module: tt.module}}}
+			}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchRequest is a single call sent as part of a batch
+type BatchRequest struct {
+	Method string
+	Args   interface{}
+	Result interface{}
+}
+
+// BatchResult is the outcome of a single call sent as part of a batch
+type BatchResult struct {
+	ID     uint64
+	Method string
+	Err    error
+}
+
+// Batch sends all requests to SPDK as a single JSON-RPC 2.0 array and decodes
+// every response into the Result of the request with the same id, whatever
+// order SPDK answers in. Failures of individual entries are reported in the
+// matching BatchResult, the returned error is only set when the batch as a
+// whole could not be exchanged. Batches always use a dedicated connection,
+// bounded by the context from dialing to reading the last response as with Call.
+func (r *Client) Batch(ctx context.Context, reqs []BatchRequest) (_ []BatchResult, err error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	ctx, childSpan := r.tracer.Start(ctx, "spdk.batch", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { endSpan(childSpan, err) }()
+
+	if childSpan.IsRecording() {
+		childSpan.SetAttributes(
+			attribute.Int("batch.size", len(reqs)),
+			attribute.String("spdk.socket", r.socket),
+			attribute.String("spdk.transport", r.transport),
+		)
+	}
+
+	requests := make([]RPCRequest, len(reqs))
+	results := make([]BatchResult, len(reqs))
+	index := make(map[uint64]int, len(reqs))
+	for i, req := range reqs {
+		id := r.nextID()
+		requests[i] = RPCRequest{
+			RPCVersion: r.rpcVersion,
+			ID:         id,
+			Method:     req.Method,
+			Params:     omitNilParams(req.Args),
+		}
+		results[i] = BatchResult{ID: id, Method: req.Method}
+		index[id] = i
+	}
+	data, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("batch: %s", err)
+	}
+
+	if !r.noRequestLog {
+		r.logger.Printf("Sending to SPDK: %s", r.redact(data))
+	}
+
+	release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("batch: %w", err)
+	}
+	raw, err := r.communicate(ctx, data)
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("batch: %w", err)
+	}
+
+	if !r.noResponseLog {
+		r.logger.Printf("Received from SPDK: %s", r.redact(raw))
+	}
+
+	// a whole-batch failure, e.g. a parse error, comes back as a single object
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var response RPCResponse
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			return nil, fmt.Errorf("batch: %s", err)
+		}
+		if response.Error.Code == 0 {
+			// a lone result is no answer to a batch, whoever sent it
+			return nil, fmt.Errorf("batch: %w: single response without error", ErrUnexpectedSpdkCallResult)
+		}
+		rpcErr := response.Error
+		rpcErr.Method = "batch"
+		return nil, &rpcErr
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("batch: %s", err)
+	}
+
+	answered := make([]bool, len(reqs))
+	for _, entry := range entries {
+		var response RPCResponse
+		decodeErr := json.Unmarshal(entry, &response)
+		i, ok := index[response.ID]
+		if !ok || answered[i] {
+			r.logger.Printf("Dropping SPDK batch response with unknown id: %s", entry)
+			continue
+		}
+		answered[i] = true
+		method := results[i].Method
+		versionErr := r.checkVersion(response.JSONRPCVersion)
+		switch {
+		case decodeErr != nil:
+			results[i].Err = fmt.Errorf("%s: %s", method, decodeErr)
+		case versionErr != nil:
+			results[i].Err = fmt.Errorf("%s: %w", method, versionErr)
+		case response.Error.Code != 0:
+			rpcErr := response.Error
+			rpcErr.Method = method
+			results[i].Err = &rpcErr
+		case reqs[i].Result != nil:
+			if err := r.decodeResult(response.Result, reqs[i].Result); err != nil {
+				results[i].Err = &ResultDecodeError{Method: method, Raw: response.Result, Err: err}
+			}
+		}
+	}
+	for i := range results {
+		if !answered[i] {
+			results[i].Err = fmt.Errorf("%s: no response received in batch", results[i].Method)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"time"
+)
+
+// BdevService is interface to all block device functions in spdk
+type BdevService interface {
+	GetBdevs(ctx context.Context, name string) ([]Bdev, error)
+	GetBdevsWithTimeout(ctx context.Context, name string, timeout time.Duration) ([]Bdev, error)
+	WaitForBdev(ctx context.Context, name string, pollInterval time.Duration) (Bdev, error)
+	GetBdevIostat(ctx context.Context, name string) (IostatResult, error)
+	BdevIostatRate(ctx context.Context, name string, interval time.Duration) (IostatRate, error)
+	ExamineBdev(ctx context.Context, name string) error
+	WaitForExamine(ctx context.Context) error
+	EnableBdevHistogram(ctx context.Context, bdevName string, enable bool) error
+	GetBdevHistogram(ctx context.Context, bdevName string) (Histogram, error)
+	SetBdevQoSLimit(ctx context.Context, bdevName string, limits QoSLimits) error
+	SetBdevOptions(ctx context.Context, params BdevOpts) error
+
+	CreateMallocBdev(ctx context.Context, params MallocBdevParams) (string, error)
+	EnsureMallocBdev(ctx context.Context, params MallocBdevParams) (bool, error)
+	DeleteMallocBdev(ctx context.Context, name string) error
+
+	CreateAioBdev(ctx context.Context, params AioParams) (string, error)
+	DeleteAioBdev(ctx context.Context, name string) error
+	CreateUringBdev(ctx context.Context, params UringParams) (string, error)
+	DeleteUringBdev(ctx context.Context, name string) error
+
+	CreateCompressBdev(ctx context.Context, params CompressParams) (string, error)
+	CreateCryptoBdev(ctx context.Context, params CryptoParams) (string, error)
+
+	CreateErrorBdev(ctx context.Context, baseBdev string) error
+	InjectBdevError(ctx context.Context, params ErrorInjectParams) error
+
+	CreatePassthruBdev(ctx context.Context, baseBdev, name string) (string, error)
+	DeletePassthruBdev(ctx context.Context, name string) error
+
+	CreateOcfBdev(ctx context.Context, params OcfParams) (string, error)
+	GetOcfBdevs(ctx context.Context) ([]OcfBdev, error)
+	GetOcfStats(ctx context.Context, name string) (OcfStats, error)
+	DeleteOcfBdev(ctx context.Context, name string) error
+
+	GetNvmeControllers(ctx context.Context, name string) ([]NvmeController, error)
+	AttachNvmeController(ctx context.Context, params NvmeAttachParams) ([]string, error)
+	DetachNvmeController(ctx context.Context, name string, addr *NvmfListenAddress) error
+
+	CreateRaidBdev(ctx context.Context, params RaidParams) error
+	GetRaidBdevs(ctx context.Context, category string) ([]string, error)
+	DeleteRaidBdev(ctx context.Context, name string) error
+}
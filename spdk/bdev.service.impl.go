@@ -0,0 +1,759 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// nvmeTransports lists the transport types bdev_nvme can attach over
+var nvmeTransports = []string{"PCIe", "TCP", "RDMA", "FC", "VFIOUSER"}
+
+// raidLevels lists the RAID levels bdev_raid can create
+var raidLevels = []string{"raid0", "raid1", "raid5f", "concat"}
+
+// raidCategories lists the categories bdev_raid_get_bdevs can filter on
+var raidCategories = []string{"all", "online", "configuring", "offline"}
+
+// ocfModes lists the cache modes bdev_ocf_create accepts
+var ocfModes = []string{"wt", "wb", "pt", "wa", "wi", "wo"}
+
+// BdevServiceImpl implements BdevService interface
+type BdevServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ BdevService = (*BdevServiceImpl)(nil)
+
+// NewBdevService is a constructor for BdevServiceImpl
+func NewBdevService(client JSONRPC) *BdevServiceImpl {
+	return &BdevServiceImpl{client}
+}
+
+// GetBdevs lists all block devices, or only the one with the given name,
+// in which case a missing device is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) GetBdevs(ctx context.Context, name string) ([]Bdev, error) {
+	var params interface{}
+	if name != "" {
+		params = &BdevGetBdevsParams{Name: name}
+	}
+	var result []Bdev
+	err := p.client.Call(ctx, "bdev_get_bdevs", params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return result, nil
+}
+
+// GetBdevsWithTimeout is GetBdevs letting SPDK wait up to timeout for the
+// named block device to appear, e.g. after a hot-plug. A device that is still
+// missing is reported as ErrBdevNotFound with the timeout in the message. The
+// call timeout of the client must be longer than timeout for the wait to finish.
+func (p *BdevServiceImpl) GetBdevsWithTimeout(ctx context.Context, name string, timeout time.Duration) ([]Bdev, error) {
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing bdev name to wait for")
+	}
+	params := BdevGetBdevsParams{
+		Name:    name,
+		Timeout: timeout.Milliseconds(),
+	}
+	var result []Bdev
+	err := p.client.Call(ctx, "bdev_get_bdevs", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && (rpcErr.Code == ENODEVCode || rpcErr.Code == ETIMEDOUTCode) {
+			return nil, &sentinelError{sentinel: ErrBdevNotFound, err: fmt.Errorf("%s did not appear within %v: %w", name, timeout, err)}
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// WaitForBdev returns the named block device, querying SPDK every pollInterval
+// until it exists or ctx is done. Unlike GetBdevsWithTimeout every query
+// returns at once, so it suits SPDK versions without the bdev_get_bdevs
+// timeout and waits longer than the call timeout. A not-found reply keeps
+// polling, any other error, a call timing out included, is returned as is,
+// and a device still missing when ctx is done is reported as ErrBdevNotFound
+// wrapping the context error.
+func (p *BdevServiceImpl) WaitForBdev(ctx context.Context, name string, pollInterval time.Duration) (Bdev, error) {
+	if name == "" {
+		return Bdev{}, status.Error(codes.InvalidArgument, "missing bdev name to wait for")
+	}
+	if pollInterval <= 0 {
+		return Bdev{}, status.Errorf(codes.InvalidArgument, "poll interval must be positive, got %v", pollInterval)
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	params := BdevGetBdevsParams{Name: name}
+	for {
+		var result []Bdev
+		err := p.client.Call(ctx, "bdev_get_bdevs", &params, &result)
+		if err == nil {
+			if len(result) != 1 {
+				msg := fmt.Sprintf("Could not find Bdev: %s, received %d devices", name, len(result))
+				log.Print(msg)
+				return Bdev{}, ErrUnexpectedSpdkCallResult
+			}
+			return result[0], nil
+		}
+		// a call cut short by ctx is not fatal, the select below reports it,
+		// but the call timeout of the client expiring on a stuck SPDK is. The
+		// connection deadline taken from ctx can expire before ctx reports it.
+		var rpcErr *RPCError
+		missing := errors.As(err, &rpcErr) && rpcErr.Code == ENODEVCode
+		deadline, ok := ctx.Deadline()
+		expired := ctx.Err() != nil || ok && !time.Now().Before(deadline)
+		if !missing && !expired {
+			log.Printf("error: %v", err)
+			return Bdev{}, err
+		}
+		select {
+		case <-ctx.Done():
+			return Bdev{}, &sentinelError{sentinel: ErrBdevNotFound, err: fmt.Errorf("%s did not appear: %w", name, ctx.Err())}
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetBdevIostat gets the IO statistics of all block devices, or only the one
+// with the given name, in which case a missing device is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) GetBdevIostat(ctx context.Context, name string) (IostatResult, error) {
+	var params interface{}
+	if name != "" {
+		params = &BdevGetIostatParams{Name: name}
+	}
+	var result IostatResult
+	err := p.client.Call(ctx, "bdev_get_iostat", params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return IostatResult{}, wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return result, nil
+}
+
+// BdevIostatRate samples the IO statistics of all block devices, or only the
+// one with the given name, twice interval apart and returns the rates in
+// between. Cancelling ctx also ends the wait between the samples.
+func (p *BdevServiceImpl) BdevIostatRate(ctx context.Context, name string, interval time.Duration) (IostatRate, error) {
+	if interval <= 0 {
+		return IostatRate{}, status.Errorf(codes.InvalidArgument, "sampling interval must be positive, got %v", interval)
+	}
+	first, err := p.GetBdevIostat(ctx, name)
+	if err != nil {
+		return IostatRate{}, err
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return IostatRate{}, status.FromContextError(ctx.Err()).Err()
+	case <-timer.C:
+	}
+	second, err := p.GetBdevIostat(ctx, name)
+	if err != nil {
+		return IostatRate{}, err
+	}
+	return second.RateSince(&first)
+}
+
+// ExamineBdev asks the bdev modules to examine the named block device, e.g. to
+// discover the GPT partitions on it. SPDK only accepts this with auto examine
+// turned off through SetBdevOptions, it examines every new device otherwise.
+func (p *BdevServiceImpl) ExamineBdev(ctx context.Context, name string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "missing bdev name to examine")
+	}
+	params := BdevExamineParams{
+		Name: name,
+	}
+	var result BdevExamineResult
+	err := p.client.Call(ctx, "bdev_examine", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not examine Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// WaitForExamine returns once SPDK has finished examining all block devices,
+// e.g. so the partitions or lvols found on a new base bdev are usable. The
+// wait ends with ctx or the call timeout of the client, whichever is first,
+// so raise WithTimeout for waits that can be longer under load.
+func (p *BdevServiceImpl) WaitForExamine(ctx context.Context) error {
+	var result BdevWaitForExamineResult
+	err := p.client.Call(ctx, "bdev_wait_for_examine", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		log.Print("Could not wait for Bdev examination")
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// EnableBdevHistogram starts or stops collecting the latency histogram of a
+// block device, enabling it again starts over from empty buckets
+func (p *BdevServiceImpl) EnableBdevHistogram(ctx context.Context, bdevName string, enable bool) error {
+	if bdevName == "" {
+		return status.Error(codes.InvalidArgument, "missing bdev name for histogram")
+	}
+	params := BdevEnableHistogramParams{
+		Name:   bdevName,
+		Enable: enable,
+	}
+	var result BdevEnableHistogramResult
+	err := p.client.Call(ctx, "bdev_enable_histogram", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not set histogram of Bdev: %s to %t", bdevName, enable)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetBdevHistogram gets the latency histogram of a block device collected
+// since EnableBdevHistogram, SPDK fails it for bdevs without one enabled
+func (p *BdevServiceImpl) GetBdevHistogram(ctx context.Context, bdevName string) (Histogram, error) {
+	if bdevName == "" {
+		return Histogram{}, status.Error(codes.InvalidArgument, "missing bdev name for histogram")
+	}
+	params := BdevGetHistogramParams{
+		Name: bdevName,
+	}
+	var result BdevGetHistogramResult
+	err := p.client.Call(ctx, "bdev_get_histogram", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return Histogram{}, wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return decodeHistogram(&result)
+}
+
+// SetBdevQoSLimit sets the rate limits of the named block device, limits left
+// at zero are cleared. The limits in effect are reported by GetBdevs as
+// AssignedRateLimits, a device that does not exist is reported as ErrBdevNotFound.
+func (p *BdevServiceImpl) SetBdevQoSLimit(ctx context.Context, bdevName string, limits QoSLimits) error {
+	if bdevName == "" {
+		return status.Error(codes.InvalidArgument, "missing bdev name to set QoS limits on")
+	}
+	params := BdevQoSParams{
+		Name:           bdevName,
+		RwIosPerSec:    limits.RwIosPerSec,
+		RwMbytesPerSec: limits.RwMbytesPerSec,
+		RMbytesPerSec:  limits.RMbytesPerSec,
+		WMbytesPerSec:  limits.WMbytesPerSec,
+	}
+	var result BdevQoSResult
+	err := p.client.Call(ctx, "bdev_set_qos_limit", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not set QoS limits of Bdev: %s", bdevName)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// SetBdevOptions sets the options of the bdev layer, unset fields keep the
+// SPDK defaults. SPDK only accepts this before framework_start_init, so
+// the target has to be started with --wait-for-rpc.
+func (p *BdevServiceImpl) SetBdevOptions(ctx context.Context, params BdevOpts) error {
+	var result BdevSetOptionsResult
+	err := p.client.Call(ctx, "bdev_set_options", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		log.Print("Could not set Bdev options")
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreateMallocBdev creates a malloc block device and returns the name SPDK assigned to it,
+// a name that is already taken is reported as ErrBdevExists
+func (p *BdevServiceImpl) CreateMallocBdev(ctx context.Context, params MallocBdevParams) (string, error) {
+	var result BdevAMalloCreateResult
+	err := p.client.Call(ctx, "bdev_malloc_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// EnsureMallocBdev creates the malloc block device unless one with the same
+// name already exists, and reports whether it was created. The existing
+// device is not compared against params. Without a name SPDK picks a new one,
+// so the device is always created.
+func (p *BdevServiceImpl) EnsureMallocBdev(ctx context.Context, params MallocBdevParams) (bool, error) {
+	if params.Name != "" {
+		_, err := p.GetBdevs(ctx, params.Name)
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, ErrBdevNotFound) {
+			return false, err
+		}
+	}
+	_, err := p.CreateMallocBdev(ctx, params)
+	if errors.Is(err, ErrBdevExists) {
+		// created concurrently since the lookup
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteMallocBdev deletes a malloc block device, a device that does not
+// exist is reported as ErrBdevNotFound so double deletes can be tolerated
+func (p *BdevServiceImpl) DeleteMallocBdev(ctx context.Context, name string) error {
+	params := BdevMallocDeleteParams{
+		Name: name,
+	}
+	var result BdevMallocDeleteResult
+	err := p.client.Call(ctx, "bdev_malloc_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode, ENOENTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete Malloc Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreateAioBdev creates a block device doing Linux AIO on a file or block
+// device and returns its name, a name that is already taken is reported as
+// ErrBdevExists
+func (p *BdevServiceImpl) CreateAioBdev(ctx context.Context, params AioParams) (string, error) {
+	if params.Name == "" || params.Filename == "" {
+		return "", status.Error(codes.InvalidArgument, "missing name or filename for aio bdev")
+	}
+	var result BdevAioCreateResult
+	err := p.client.Call(ctx, "bdev_aio_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// DeleteAioBdev deletes an AIO block device leaving its file in place, a
+// device that does not exist is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DeleteAioBdev(ctx context.Context, name string) error {
+	params := BdevAioDeleteParams{
+		Name: name,
+	}
+	var result BdevAioDeleteResult
+	err := p.client.Call(ctx, "bdev_aio_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode, ENOENTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete Aio Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreateUringBdev creates a block device doing io_uring on a file or block
+// device and returns its name, a name that is already taken is reported as
+// ErrBdevExists. SPDK only has the method when built with uring support.
+func (p *BdevServiceImpl) CreateUringBdev(ctx context.Context, params UringParams) (string, error) {
+	if params.Name == "" || params.Filename == "" {
+		return "", status.Error(codes.InvalidArgument, "missing name or filename for uring bdev")
+	}
+	var result BdevUringCreateResult
+	err := p.client.Call(ctx, "bdev_uring_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// DeleteUringBdev deletes a uring block device leaving its file in place, a
+// device that does not exist is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DeleteUringBdev(ctx context.Context, name string) error {
+	params := BdevUringDeleteParams{
+		Name: name,
+	}
+	var result BdevUringDeleteResult
+	err := p.client.Call(ctx, "bdev_uring_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode, ENOENTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete Uring Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreateCompressBdev creates a compress block device on top of a base bdev and
+// returns the name SPDK assigned to it, a base bdev that does not exist is
+// reported as ErrBdevNotFound and one that is already compressed as ErrBdevExists
+func (p *BdevServiceImpl) CreateCompressBdev(ctx context.Context, params CompressParams) (string, error) {
+	if params.BaseBdevName == "" || params.PmPath == "" {
+		return "", status.Error(codes.InvalidArgument, "missing base_bdev_name or pm_path for compress bdev")
+	}
+	var result BdevCompressCreateResult
+	err := p.client.Call(ctx, "bdev_compress_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		err = wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// CreateCryptoBdev creates a crypto block device on top of a base bdev and
+// returns its name, exactly one of KeyName or Key has to be given and a name
+// that is already taken is reported as ErrBdevExists
+func (p *BdevServiceImpl) CreateCryptoBdev(ctx context.Context, params CryptoParams) (string, error) {
+	if params.BaseBdevName == "" || params.Name == "" {
+		return "", status.Error(codes.InvalidArgument, "missing base_bdev_name or name for crypto bdev")
+	}
+	if (params.KeyName == "") == (params.Key == "") {
+		return "", status.Error(codes.InvalidArgument, "crypto bdev needs either key_name or an inline key")
+	}
+	var result BdevCryptoCreateResult
+	err := p.client.Call(ctx, "bdev_crypto_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// CreateErrorBdev creates an Error Block Device named EE_<baseBdev> on top of
+// baseBdev, which passes I/O through until errors are injected into it
+func (p *BdevServiceImpl) CreateErrorBdev(ctx context.Context, baseBdev string) error {
+	if baseBdev == "" {
+		return status.Error(codes.InvalidArgument, "missing base bdev name for error bdev")
+	}
+	params := BdevErrorCreateParams{
+		BaseName: baseBdev,
+	}
+	var result BdevErrorCreateResult
+	err := p.client.Call(ctx, "bdev_error_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create Error Bdev on: %s", baseBdev)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// InjectBdevError makes the next I/Os of the given type on an Error Block
+// Device fail as described by params, an error bdev that does not exist is
+// reported as ErrBdevNotFound
+func (p *BdevServiceImpl) InjectBdevError(ctx context.Context, params ErrorInjectParams) error {
+	if params.Name == "" || params.IoType == "" || params.ErrorType == "" {
+		return status.Error(codes.InvalidArgument, "missing name, io_type or error_type for error injection")
+	}
+	var result BdevErrorInjectResult
+	err := p.client.Call(ctx, "bdev_error_inject_error", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not inject %s errors into: %s", params.ErrorType, params.Name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreatePassthruBdev creates a passthru block device on top of a base bdev and
+// returns its name, a name that is already taken is reported as ErrBdevExists
+func (p *BdevServiceImpl) CreatePassthruBdev(ctx context.Context, baseBdev, name string) (string, error) {
+	if baseBdev == "" || name == "" {
+		return "", status.Error(codes.InvalidArgument, "missing base bdev name or name for passthru bdev")
+	}
+	params := BdevPassthruCreateParams{
+		BaseBdevName: baseBdev,
+		Name:         name,
+	}
+	var result BdevPassthruCreateResult
+	err := p.client.Call(ctx, "bdev_passthru_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// DeletePassthruBdev deletes a passthru block device leaving its base bdev in
+// place, a device that does not exist is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DeletePassthruBdev(ctx context.Context, name string) error {
+	params := BdevPassthruDeleteParams{
+		Name: name,
+	}
+	var result BdevPassthruDeleteResult
+	err := p.client.Call(ctx, "bdev_passthru_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode, ENOENTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete Passthru Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// CreateOcfBdev creates an OCF cache block device and returns its name, a name
+// that is already taken is reported as ErrBdevExists. SPDK accepts cache and
+// core bdevs that do not exist yet and starts the device once they appear.
+func (p *BdevServiceImpl) CreateOcfBdev(ctx context.Context, params OcfParams) (string, error) {
+	if params.Name == "" || params.CacheBdevName == "" || params.CoreBdevName == "" {
+		return "", status.Error(codes.InvalidArgument, "missing name, cache_bdev_name or core_bdev_name for ocf bdev")
+	}
+	if !containsFold(ocfModes, params.Mode) {
+		return "", status.Errorf(codes.InvalidArgument, "invalid cache mode %q, expected one of %v", params.Mode, ocfModes)
+	}
+	var result BdevOcfCreateResult
+	err := p.client.Call(ctx, "bdev_ocf_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrBdevExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// GetOcfBdevs lists all OCF cache block devices with the state of their cache
+// and core bdevs
+func (p *BdevServiceImpl) GetOcfBdevs(ctx context.Context) ([]OcfBdev, error) {
+	var result []OcfBdev
+	err := p.client.Call(ctx, "bdev_ocf_get_bdevs", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOcfStats gets the usage, request, block and error statistics of an OCF
+// cache block device, a device that does not exist is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) GetOcfStats(ctx context.Context, name string) (OcfStats, error) {
+	params := BdevOcfGetStatsParams{
+		Name: name,
+	}
+	var result OcfStats
+	err := p.client.Call(ctx, "bdev_ocf_get_stats", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return OcfStats{}, wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return result, nil
+}
+
+// DeleteOcfBdev deletes an OCF cache block device leaving its cache and core
+// bdevs in place, a device that does not exist is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DeleteOcfBdev(ctx context.Context, name string) error {
+	params := BdevOcfDeleteParams{
+		Name: name,
+	}
+	var result BdevOcfDeleteResult
+	err := p.client.Call(ctx, "bdev_ocf_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode, ENOENTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete Ocf Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetNvmeControllers lists all attached NVMe controllers with the state of
+// each of their paths, or only the one with the given name, in which case a
+// controller that is not attached is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) GetNvmeControllers(ctx context.Context, name string) ([]NvmeController, error) {
+	var params interface{}
+	if name != "" {
+		params = &BdevNvmeGetControllerParams{Name: name}
+	}
+	var result []NvmeController
+	err := p.client.Call(ctx, "bdev_nvme_get_controllers", params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return result, nil
+}
+
+// AttachNvmeController attaches a local or remote NVMe controller and returns the
+// names of the bdevs created for its namespaces. A controller that is already
+// attached is reported as ErrNvmeControllerExists, transport failures keep their
+// own error so they can be retried.
+func (p *BdevServiceImpl) AttachNvmeController(ctx context.Context, params NvmeAttachParams) ([]string, error) {
+	if err := validateNvmeTransport(params.Trtype); err != nil {
+		return nil, err
+	}
+	var result []string
+	err := p.client.Call(ctx, "bdev_nvme_attach_controller", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, wrapRPCError(err, ErrNvmeControllerExists, EEXISTCode)
+	}
+	return result, nil
+}
+
+// DetachNvmeController detaches an NVMe controller, or only the path to it at
+// addr when given, a controller that is not attached is reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DetachNvmeController(ctx context.Context, name string, addr *NvmfListenAddress) error {
+	params := NvmeDetachParams{
+		Name: name,
+	}
+	if addr != nil {
+		if err := validateNvmeTransport(addr.Trtype); err != nil {
+			return err
+		}
+		params.Trtype = addr.Trtype
+		params.Traddr = addr.Traddr
+		params.Adrfam = addr.Adrfam
+		params.Trsvcid = addr.Trsvcid
+	}
+	var result BdevNvmeDetachControllerResult
+	err := p.client.Call(ctx, "bdev_nvme_detach_controller", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not detach NVMe controller: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// validateNvmeTransport rejects transport types bdev_nvme does not support
+func validateNvmeTransport(trtype string) error {
+	if !containsFold(nvmeTransports, trtype) {
+		return status.Errorf(codes.InvalidArgument, "invalid trtype %q, expected one of %v", trtype, nvmeTransports)
+	}
+	return nil
+}
+
+// CreateRaidBdev creates a RAID bdev from the base bdevs, the level and the list
+// of base bdevs are checked before anything is sent to SPDK
+func (p *BdevServiceImpl) CreateRaidBdev(ctx context.Context, params RaidParams) error {
+	if !containsFold(raidLevels, params.RaidLevel) {
+		return status.Errorf(codes.InvalidArgument, "invalid raid_level %q, expected one of %v", params.RaidLevel, raidLevels)
+	}
+	if len(params.BaseBdevs) == 0 {
+		return status.Error(codes.InvalidArgument, "missing base_bdevs")
+	}
+	var result BdevRaidCreateResult
+	err := p.client.Call(ctx, "bdev_raid_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create RAID Bdev: %s", params.Name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetRaidBdevs lists the names of the RAID bdevs in the given category,
+// one of all, online, configuring or offline
+func (p *BdevServiceImpl) GetRaidBdevs(ctx context.Context, category string) ([]string, error) {
+	if !containsFold(raidCategories, category) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid category %q, expected one of %v", category, raidCategories)
+	}
+	params := BdevRaidGetBdevsParams{
+		Category: category,
+	}
+	// older SPDK releases list names, newer ones list objects carrying a name
+	var result []json.RawMessage
+	err := p.client.Call(ctx, "bdev_raid_get_bdevs", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(result))
+	for _, entry := range result {
+		var raid struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(entry, &raid.Name); err != nil {
+			if err := json.Unmarshal(entry, &raid); err != nil {
+				return nil, fmt.Errorf("bdev_raid_get_bdevs: %s", err)
+			}
+		}
+		names = append(names, raid.Name)
+	}
+	return names, nil
+}
+
+// DeleteRaidBdev deletes a RAID bdev, a device that does not exist is
+// reported as ErrBdevNotFound
+func (p *BdevServiceImpl) DeleteRaidBdev(ctx context.Context, name string) error {
+	params := BdevRaidDeleteParams{
+		Name: name,
+	}
+	var result BdevRaidDeleteResult
+	err := p.client.Call(ctx, "bdev_raid_delete", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete RAID Bdev: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
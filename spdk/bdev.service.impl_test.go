@@ -0,0 +1,1644 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBdevService_GetBdevs(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		mock     *MockJSONRPC
+		want     []Bdev
+		wantArgs interface{}
+		wantErr  error
+	}{
+		"list all": {
+			"",
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc0","block_size":512,"num_blocks":64,"driver_specific":{}},{"name":"Malloc1"}]`),
+			[]Bdev{
+				{Name: "Malloc0", BlockSize: 512, NumBlocks: 64, DriverSpecific: map[string]json.RawMessage{}},
+				{Name: "Malloc1"},
+			},
+			nil,
+			nil,
+		},
+		"single bdev": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc0","aliases":["a0"]}]`),
+			[]Bdev{{Name: "Malloc0", Aliases: []string{"a0"}}},
+			&BdevGetBdevsParams{Name: "Malloc0"},
+			nil,
+		},
+		"rate limits": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc0","assigned_rate_limits":{"rw_ios_per_sec":10000,"rw_mbytes_per_sec":0,"r_mbytes_per_sec":100,"w_mbytes_per_sec":50}}]`),
+			[]Bdev{{Name: "Malloc0", AssignedRateLimits: QoSLimits{RwIosPerSec: 10000, RMbytesPerSec: 100, WMbytesPerSec: 50}}},
+			&BdevGetBdevsParams{Name: "Malloc0"},
+			nil,
+		},
+		"not found": {
+			"Missing",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", &RPCError{Method: "bdev_get_bdevs", Code: ENODEVCode, Message: "No such device"}),
+			nil,
+			&BdevGetBdevsParams{Name: "Missing"},
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := NewBdevService(tt.mock)
+			got, err := service.GetBdevs(context.Background(), tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantErr != nil && status.Code(err) != codes.NotFound {
+				t.Error("code: expected NotFound received", status.Code(err))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetBdevsWithTimeout(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		mock    *MockJSONRPC
+		want    []Bdev
+		wantErr error
+	}{
+		"appeared": {
+			"Nvme0n1",
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Nvme0n1","block_size":4096}]`),
+			[]Bdev{{Name: "Nvme0n1", BlockSize: 4096}},
+			nil,
+		},
+		"timed out": {
+			"Nvme0n1",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			nil,
+			ErrBdevNotFound,
+		},
+		"spdk timed out": {
+			"Nvme0n1",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", &RPCError{Code: ETIMEDOUTCode, Message: "Connection timed out"}),
+			nil,
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).GetBdevsWithTimeout(context.Background(), tt.name, 1500*time.Millisecond)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			want := &BdevGetBdevsParams{Name: tt.name, Timeout: 1500}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetBdevsWithTimeoutMissingName(t *testing.T) {
+	mock := NewMockJSONRPC()
+	_, err := NewBdevService(mock).GetBdevsWithTimeout(context.Background(), "", time.Second)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("code: expected", codes.InvalidArgument, "received", err)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Error("calls: expected none received", calls)
+	}
+}
+
+func TestBdevService_WaitForBdev(t *testing.T) {
+	tests := map[string]struct {
+		misses    int32
+		errCode   int
+		wantCalls int32
+		want      Bdev
+		wantErr   error
+		wantCode  int
+	}{
+		"appeared after polling": {
+			2, ENODEVCode, 3,
+			Bdev{Name: "Nvme0n1", BlockSize: 4096},
+			nil,
+			0,
+		},
+		"never appeared": {
+			1 << 30, ENODEVCode, -1,
+			Bdev{},
+			context.DeadlineExceeded,
+			0,
+		},
+		"fatal error": {
+			1 << 30, InvalidParamsCode, 1,
+			Bdev{},
+			nil,
+			InvalidParamsCode,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			misses, errCode := tt.misses, tt.errCode
+			var calls int32
+			serve(ln, func(req RPCRequest) string {
+				if atomic.AddInt32(&calls, 1) <= misses {
+					return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":%d,"message":"No such device"}}`, req.ID, errCode)
+				}
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":[{"name":"Nvme0n1","block_size":4096}]}`, req.ID)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			got, err := NewBdevService(client).WaitForBdev(ctx, "Nvme0n1", 10*time.Millisecond)
+			var rpcErr *RPCError
+			switch {
+			case tt.wantCode != 0:
+				if !errors.As(err, &rpcErr) || rpcErr.Code != tt.wantCode {
+					t.Error("code: expected", tt.wantCode, "received", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantErr == context.DeadlineExceeded && !errors.Is(err, ErrBdevNotFound) {
+				t.Error("error: expected", ErrBdevNotFound, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if n := atomic.LoadInt32(&calls); tt.wantCalls >= 0 && n != tt.wantCalls {
+				t.Error("calls: expected", tt.wantCalls, "received", n)
+			}
+		})
+	}
+}
+
+func TestBdevService_WaitForBdevStuckServer(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}), WithTimeout(50*time.Millisecond))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	// a stuck SPDK reads the request but never answers
+	hold := make(chan struct{})
+	defer close(hold)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+		<-hold
+	}()
+
+	// without a deadline on ctx, only the call timeout ends the wait
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewBdevService(client).WaitForBdev(context.Background(), "Nvme0n1", 10*time.Millisecond)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.DeadlineExceeded || errors.Is(err, ErrBdevNotFound) {
+			t.Error("code: expected", codes.DeadlineExceeded, "received", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait: expected the call timeout to end it")
+	}
+}
+
+func TestBdevService_WaitForBdevInvalidArgs(t *testing.T) {
+	tests := map[string]struct {
+		name         string
+		pollInterval time.Duration
+	}{
+		"missing name":      {"", time.Second},
+		"zero interval":     {"Nvme0n1", 0},
+		"negative interval": {"Nvme0n1", -time.Second},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC()
+			_, err := NewBdevService(mock).WaitForBdev(context.Background(), tt.name, tt.pollInterval)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Error("code: expected", codes.InvalidArgument, "received", err)
+			}
+			if calls := mock.Calls(); len(calls) != 0 {
+				t.Error("calls: expected none received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetBdevIostat(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		mock     *MockJSONRPC
+		want     IostatResult
+		wantArgs interface{}
+		wantErr  error
+	}{
+		"all bdevs": {
+			"",
+			NewMockJSONRPC().On("bdev_get_iostat", `{"tick_rate":2000000000,"ticks":4000000000,"bdevs":[{"name":"Malloc0","bytes_read":4096,"num_read_ops":1,"read_latency_ticks":2000,"queue_depth":3}]}`),
+			IostatResult{
+				TickRate: 2000000000,
+				Ticks:    4000000000,
+				Bdevs:    []BdevIostat{{Name: "Malloc0", BytesRead: 4096, NumReadOps: 1, ReadLatencyTicks: 2000, QueueDepth: 3}},
+			},
+			nil,
+			nil,
+		},
+		"single bdev": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_get_iostat", `{"tick_rate":1,"ticks":2,"bdevs":[{"name":"Malloc0"}]}`),
+			IostatResult{TickRate: 1, Ticks: 2, Bdevs: []BdevIostat{{Name: "Malloc0"}}},
+			&BdevGetIostatParams{Name: "Malloc0"},
+			nil,
+		},
+		"not found": {
+			"Missing",
+			NewMockJSONRPC().OnError("bdev_get_iostat", &RPCError{Method: "bdev_get_iostat", Code: ENODEVCode, Message: "No such device"}),
+			IostatResult{},
+			&BdevGetIostatParams{Name: "Missing"},
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).GetBdevIostat(context.Background(), tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_BdevIostatRate(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	var samples int32
+	serve(ln, func(req RPCRequest) string {
+		n := atomic.AddInt32(&samples, 1)
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"tick_rate":1000,"ticks":%d,"bdevs":[{"name":"Malloc0","num_read_ops":%d,"bytes_read":%d}]}}`,
+			req.ID, n*500, n*100, n*1048576)
+	})
+
+	got, err := NewBdevService(client).BdevIostatRate(context.Background(), "Malloc0", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := IostatRate{Elapsed: 500 * time.Millisecond, ReadIOPS: 200, ReadMiBps: 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestBdevService_BdevIostatRateStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := map[string]struct {
+		ctx      context.Context
+		interval time.Duration
+		wantCode codes.Code
+		calls    int
+	}{
+		"cancelled while waiting": {ctx, time.Minute, codes.Canceled, 1},
+		"zero interval":           {context.Background(), 0, codes.InvalidArgument, 0},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("bdev_get_iostat", `{"tick_rate":1000,"ticks":1,"bdevs":[]}`)
+			_, err := NewBdevService(mock).BdevIostatRate(tt.ctx, "", tt.interval)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if calls := mock.Calls(); len(calls) != tt.calls {
+				t.Error("calls: expected", tt.calls, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_ExamineBdev(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"examined": {
+			NewMockJSONRPC().On("bdev_examine", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_examine", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).ExamineBdev(context.Background(), "Nvme0n1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{{Method: "bdev_examine", Args: &BdevExamineParams{Name: "Nvme0n1"}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_ExamineBdevMissingName(t *testing.T) {
+	mock := NewMockJSONRPC()
+	err := NewBdevService(mock).ExamineBdev(context.Background(), "")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("code: expected", codes.InvalidArgument, "received", err)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Error("calls: expected none received", calls)
+	}
+}
+
+func TestBdevService_WaitForExamine(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"examined": {
+			NewMockJSONRPC().On("bdev_wait_for_examine", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_wait_for_examine", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).WaitForExamine(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{{Method: "bdev_wait_for_examine"}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_WaitForExamineDeadline(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	hold := make(chan struct{})
+	defer close(hold)
+	serve(ln, func(req RPCRequest) string {
+		<-hold
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := NewBdevService(client).WaitForExamine(ctx)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Error("code: expected", codes.DeadlineExceeded, "received", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Error("elapsed: expected the deadline to end the wait received", elapsed)
+	}
+}
+
+func TestBdevService_EnableBdevHistogram(t *testing.T) {
+	tests := map[string]struct {
+		bdevName string
+		mock     *MockJSONRPC
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"enabled": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_enable_histogram", true),
+			codes.OK,
+			nil,
+		},
+		"not found": {
+			"Malloc0",
+			NewMockJSONRPC().OnError("bdev_enable_histogram", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			codes.NotFound,
+			ErrBdevNotFound,
+		},
+		"unexpected result": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_enable_histogram", false),
+			codes.FailedPrecondition,
+			ErrUnexpectedSpdkCallResult,
+		},
+		"no name": {
+			"",
+			NewMockJSONRPC(),
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).EnableBdevHistogram(context.Background(), tt.bdevName, true)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			want := &BdevEnableHistogramParams{Name: tt.bdevName, Enable: true}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetBdevHistogram(t *testing.T) {
+	result := BdevGetHistogramResult{Histogram: encodeHistogram(7, map[int]uint64{5: 3}), TscRate: 1000000000, BucketShift: 7}
+	tests := map[string]struct {
+		mock     *MockJSONRPC
+		want     Histogram
+		wantCode codes.Code
+	}{
+		"decoded": {
+			NewMockJSONRPC().On("bdev_get_histogram", result),
+			Histogram{TscRate: 1000000000, BucketShift: 7, Buckets: []HistogramBucket{
+				{Start: 5 * time.Nanosecond, End: 6 * time.Nanosecond, Count: 3},
+			}},
+			codes.OK,
+		},
+		"not found": {
+			NewMockJSONRPC().OnError("bdev_get_histogram", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			Histogram{},
+			codes.NotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).GetBdevHistogram(context.Background(), "Malloc0")
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("histogram: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestBdevService_SetBdevQoSLimit(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		limits   QoSLimits
+		mock     *MockJSONRPC
+		wantJSON string
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"iops and bandwidth": {
+			"Malloc0",
+			QoSLimits{RwIosPerSec: 10000, RwMbytesPerSec: 200},
+			NewMockJSONRPC().On("bdev_set_qos_limit", true),
+			`{"name":"Malloc0","rw_ios_per_sec":10000,"rw_mbytes_per_sec":200,"r_mbytes_per_sec":0,"w_mbytes_per_sec":0}`,
+			codes.OK,
+			nil,
+		},
+		"clear all": {
+			"Malloc0",
+			QoSLimits{},
+			NewMockJSONRPC().On("bdev_set_qos_limit", true),
+			`{"name":"Malloc0","rw_ios_per_sec":0,"rw_mbytes_per_sec":0,"r_mbytes_per_sec":0,"w_mbytes_per_sec":0}`,
+			codes.OK,
+			nil,
+		},
+		"not found": {
+			"Missing",
+			QoSLimits{RwIosPerSec: 10000},
+			NewMockJSONRPC().OnError("bdev_set_qos_limit", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			`{"name":"Missing","rw_ios_per_sec":10000,"rw_mbytes_per_sec":0,"r_mbytes_per_sec":0,"w_mbytes_per_sec":0}`,
+			codes.NotFound,
+			ErrBdevNotFound,
+		},
+		"unexpected result": {
+			"Malloc0",
+			QoSLimits{},
+			NewMockJSONRPC().On("bdev_set_qos_limit", false),
+			`{"name":"Malloc0","rw_ios_per_sec":0,"rw_mbytes_per_sec":0,"r_mbytes_per_sec":0,"w_mbytes_per_sec":0}`,
+			codes.FailedPrecondition,
+			ErrUnexpectedSpdkCallResult,
+		},
+		"missing name": {
+			"",
+			QoSLimits{RwIosPerSec: 10000},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).SetBdevQoSLimit(context.Background(), tt.name, tt.limits)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			calls := tt.mock.Calls()
+			if tt.wantJSON == "" {
+				if len(calls) != 0 {
+					t.Error("calls: expected none received", calls)
+				}
+				return
+			}
+			if len(calls) != 1 {
+				t.Fatal("calls: expected 1 received", calls)
+			}
+			data, _ := json.Marshal(calls[0].Args)
+			if string(data) != tt.wantJSON {
+				t.Error("args: expected", tt.wantJSON, "received", string(data))
+			}
+		})
+	}
+}
+
+func TestBdevService_SetBdevOptions(t *testing.T) {
+	autoExamine := false
+	notStartup := &RPCError{Code: EPERMCode, Message: "Method may only be called in state STARTUP"}
+	tests := map[string]struct {
+		params   BdevOpts
+		mock     *MockJSONRPC
+		wantJSON string
+		wantErr  error
+	}{
+		"disable auto examine": {
+			BdevOpts{AutoExamine: &autoExamine},
+			NewMockJSONRPC().On("bdev_set_options", true),
+			`{"bdev_auto_examine":false}`,
+			nil,
+		},
+		"pool sizes": {
+			BdevOpts{BdevIoPoolSize: 65535, BdevIoCacheSize: 256},
+			NewMockJSONRPC().On("bdev_set_options", true),
+			`{"bdev_io_pool_size":65535,"bdev_io_cache_size":256}`,
+			nil,
+		},
+		"after init": {
+			BdevOpts{AutoExamine: &autoExamine},
+			NewMockJSONRPC().OnError("bdev_set_options", notStartup),
+			`{"bdev_auto_examine":false}`,
+			notStartup,
+		},
+		"unexpected result": {
+			BdevOpts{},
+			NewMockJSONRPC().On("bdev_set_options", false),
+			`{}`,
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).SetBdevOptions(context.Background(), tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			calls := tt.mock.Calls()
+			if len(calls) != 1 {
+				t.Fatal("calls: expected 1 received", calls)
+			}
+			data, _ := json.Marshal(calls[0].Args)
+			if string(data) != tt.wantJSON {
+				t.Error("args: expected", tt.wantJSON, "received", string(data))
+			}
+		})
+	}
+}
+
+func TestBdevService_EnsureMallocBdev(t *testing.T) {
+	exists := &RPCError{Code: EEXISTCode, Message: "File exists"}
+	notFound := &RPCError{Code: ENODEVCode, Message: "No such device"}
+	tests := map[string]struct {
+		name        string
+		mock        *MockJSONRPC
+		wantCreated bool
+		wantErr     error
+		wantMethods []string
+	}{
+		"missing": {
+			"Malloc0",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", notFound).On("bdev_malloc_create", `"Malloc0"`),
+			true,
+			nil,
+			[]string{"bdev_get_bdevs", "bdev_malloc_create"},
+		},
+		"existing": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc0"}]`),
+			false,
+			nil,
+			[]string{"bdev_get_bdevs"},
+		},
+		"created concurrently": {
+			"Malloc0",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", notFound).OnError("bdev_malloc_create", exists),
+			false,
+			nil,
+			[]string{"bdev_get_bdevs", "bdev_malloc_create"},
+		},
+		"unnamed": {
+			"",
+			NewMockJSONRPC().On("bdev_malloc_create", `"Malloc3"`),
+			true,
+			nil,
+			[]string{"bdev_malloc_create"},
+		},
+		"lookup failure": {
+			"Malloc0",
+			NewMockJSONRPC().OnError("bdev_get_bdevs", ErrFailedSpdkCall),
+			false,
+			ErrFailedSpdkCall,
+			[]string{"bdev_get_bdevs"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := MallocBdevParams{Name: tt.name, NumBlocks: 64, BlockSize: 512}
+			created, err := NewBdevService(tt.mock).EnsureMallocBdev(context.Background(), params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if created != tt.wantCreated {
+				t.Error("created: expected", tt.wantCreated, "received", created)
+			}
+			var methods []string
+			for _, call := range tt.mock.Calls() {
+				methods = append(methods, call.Method)
+			}
+			if !reflect.DeepEqual(methods, tt.wantMethods) {
+				t.Error("methods: expected", tt.wantMethods, "received", methods)
+			}
+		})
+	}
+}
+
+func TestBdevService_DeleteMallocBdev(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"deleted": {
+			NewMockJSONRPC().On("bdev_malloc_delete", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_malloc_delete", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+		"already deleted": {
+			NewMockJSONRPC().OnError("bdev_malloc_delete", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).DeleteMallocBdev(context.Background(), "Malloc0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{{Method: "bdev_malloc_delete", Args: &BdevMallocDeleteParams{Name: "Malloc0"}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateFileBdev(t *testing.T) {
+	aio := func(svc *BdevServiceImpl, name string) (string, error) {
+		return svc.CreateAioBdev(context.Background(), AioParams{Name: name, Filename: "/tmp/aio0", BlockSize: 512})
+	}
+	uring := func(svc *BdevServiceImpl, name string) (string, error) {
+		return svc.CreateUringBdev(context.Background(), UringParams{Name: name, Filename: "/tmp/uring0"})
+	}
+	tests := map[string]struct {
+		create   func(*BdevServiceImpl, string) (string, error)
+		name     string
+		mock     *MockJSONRPC
+		wantArgs interface{}
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"aio created": {
+			aio,
+			"Aio0",
+			NewMockJSONRPC().On("bdev_aio_create", `"Aio0"`),
+			&AioParams{Name: "Aio0", Filename: "/tmp/aio0", BlockSize: 512},
+			codes.OK,
+			nil,
+		},
+		"aio already exists": {
+			aio,
+			"Aio0",
+			NewMockJSONRPC().OnError("bdev_aio_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			&AioParams{Name: "Aio0", Filename: "/tmp/aio0", BlockSize: 512},
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"uring created": {
+			uring,
+			"Uring0",
+			NewMockJSONRPC().On("bdev_uring_create", `"Uring0"`),
+			&UringParams{Name: "Uring0", Filename: "/tmp/uring0"},
+			codes.OK,
+			nil,
+		},
+		"uring not supported": {
+			uring,
+			"Uring0",
+			NewMockJSONRPC(),
+			&UringParams{Name: "Uring0", Filename: "/tmp/uring0"},
+			codes.Unimplemented,
+			nil,
+		},
+		"no name": {
+			aio,
+			"",
+			NewMockJSONRPC(),
+			nil,
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.create(NewBdevService(tt.mock), tt.name)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := ""
+			if tt.wantCode == codes.OK {
+				want = tt.name
+			}
+			if got != want {
+				t.Error("response: expected", want, "received", got)
+			}
+			if tt.wantArgs == nil {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_DeleteFileBdev(t *testing.T) {
+	tests := map[string]struct {
+		delete   func(*BdevServiceImpl) error
+		mock     *MockJSONRPC
+		wantCall MockCall
+		wantErr  error
+	}{
+		"aio deleted": {
+			func(svc *BdevServiceImpl) error { return svc.DeleteAioBdev(context.Background(), "Aio0") },
+			NewMockJSONRPC().On("bdev_aio_delete", true),
+			MockCall{Method: "bdev_aio_delete", Args: &BdevAioDeleteParams{Name: "Aio0"}},
+			nil,
+		},
+		"aio already deleted": {
+			func(svc *BdevServiceImpl) error { return svc.DeleteAioBdev(context.Background(), "Aio0") },
+			NewMockJSONRPC().OnError("bdev_aio_delete", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			MockCall{Method: "bdev_aio_delete", Args: &BdevAioDeleteParams{Name: "Aio0"}},
+			ErrBdevNotFound,
+		},
+		"uring deleted": {
+			func(svc *BdevServiceImpl) error { return svc.DeleteUringBdev(context.Background(), "Uring0") },
+			NewMockJSONRPC().On("bdev_uring_delete", true),
+			MockCall{Method: "bdev_uring_delete", Args: &BdevUringDeleteParams{Name: "Uring0"}},
+			nil,
+		},
+		"uring unexpected result": {
+			func(svc *BdevServiceImpl) error { return svc.DeleteUringBdev(context.Background(), "Uring0") },
+			NewMockJSONRPC().On("bdev_uring_delete", false),
+			MockCall{Method: "bdev_uring_delete", Args: &BdevUringDeleteParams{Name: "Uring0"}},
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.delete(NewBdevService(tt.mock))
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{tt.wantCall}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateCompressBdev(t *testing.T) {
+	tests := map[string]struct {
+		params   CompressParams
+		mock     *MockJSONRPC
+		want     string
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"created": {
+			CompressParams{BaseBdevName: "Nvme0n1", PmPath: "/tmp/pmem", LbSize: 4096},
+			NewMockJSONRPC().On("bdev_compress_create", `"COMP_Nvme0n1"`),
+			"COMP_Nvme0n1",
+			codes.OK,
+			nil,
+		},
+		"base bdev not found": {
+			CompressParams{BaseBdevName: "Missing", PmPath: "/tmp/pmem"},
+			NewMockJSONRPC().OnError("bdev_compress_create", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			"",
+			codes.NotFound,
+			ErrBdevNotFound,
+		},
+		"already exists": {
+			CompressParams{BaseBdevName: "Nvme0n1", PmPath: "/tmp/pmem"},
+			NewMockJSONRPC().OnError("bdev_compress_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"missing pm path": {
+			CompressParams{BaseBdevName: "Nvme0n1"},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).CreateCompressBdev(context.Background(), tt.params)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateCryptoBdev(t *testing.T) {
+	tests := map[string]struct {
+		params   CryptoParams
+		mock     *MockJSONRPC
+		want     string
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"key name": {
+			CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0", KeyName: "key0"},
+			NewMockJSONRPC().On("bdev_crypto_create", `"Crypto0"`),
+			"Crypto0",
+			codes.OK,
+			nil,
+		},
+		"inline key": {
+			CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0", Key: "0123456789abcdef", Cipher: "AES_CBC"},
+			NewMockJSONRPC().On("bdev_crypto_create", `"Crypto0"`),
+			"Crypto0",
+			codes.OK,
+			nil,
+		},
+		"already exists": {
+			CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0", KeyName: "key0"},
+			NewMockJSONRPC().OnError("bdev_crypto_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"no key": {
+			CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0"},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+		"both keys": {
+			CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0", KeyName: "key0", Key: "0123456789abcdef"},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).CreateCryptoBdev(context.Background(), tt.params)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if tt.wantCode == codes.InvalidArgument && len(tt.mock.Calls()) != 0 {
+				t.Error("calls: expected none received", tt.mock.Calls())
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateErrorBdev(t *testing.T) {
+	tests := map[string]struct {
+		baseBdev string
+		mock     *MockJSONRPC
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"created": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_error_create", true),
+			codes.OK,
+			nil,
+		},
+		"already exists": {
+			"Malloc0",
+			NewMockJSONRPC().OnError("bdev_error_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"unexpected result": {
+			"Malloc0",
+			NewMockJSONRPC().On("bdev_error_create", false),
+			codes.FailedPrecondition,
+			ErrUnexpectedSpdkCallResult,
+		},
+		"no base bdev": {
+			"",
+			NewMockJSONRPC(),
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).CreateErrorBdev(context.Background(), tt.baseBdev)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			want := &BdevErrorCreateParams{BaseName: tt.baseBdev}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_InjectBdevError(t *testing.T) {
+	valid := ErrorInjectParams{Name: "EE_Malloc0", IoType: "write", ErrorType: "failure", Num: 3}
+	tests := map[string]struct {
+		params   ErrorInjectParams
+		mock     *MockJSONRPC
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"injected": {
+			valid,
+			NewMockJSONRPC().On("bdev_error_inject_error", true),
+			codes.OK,
+			nil,
+		},
+		"not found": {
+			valid,
+			NewMockJSONRPC().OnError("bdev_error_inject_error", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			codes.NotFound,
+			ErrBdevNotFound,
+		},
+		"unexpected result": {
+			valid,
+			NewMockJSONRPC().On("bdev_error_inject_error", false),
+			codes.FailedPrecondition,
+			ErrUnexpectedSpdkCallResult,
+		},
+		"no error type": {
+			ErrorInjectParams{Name: "EE_Malloc0", IoType: "write"},
+			NewMockJSONRPC(),
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).InjectBdevError(context.Background(), tt.params)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, &tt.params) {
+				t.Error("args: expected", &tt.params, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreatePassthruBdev(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		mock     *MockJSONRPC
+		want     string
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"created": {
+			"Passthru0",
+			NewMockJSONRPC().On("bdev_passthru_create", `"Passthru0"`),
+			"Passthru0",
+			codes.OK,
+			nil,
+		},
+		"already exists": {
+			"Passthru0",
+			NewMockJSONRPC().OnError("bdev_passthru_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"no name": {
+			"",
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).CreatePassthruBdev(context.Background(), "Malloc0", tt.name)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			want := &BdevPassthruCreateParams{BaseBdevName: "Malloc0", Name: tt.name}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_DeletePassthruBdev(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"deleted": {
+			NewMockJSONRPC().On("bdev_passthru_delete", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_passthru_delete", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+		"already deleted": {
+			NewMockJSONRPC().OnError("bdev_passthru_delete", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).DeletePassthruBdev(context.Background(), "Passthru0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{{Method: "bdev_passthru_delete", Args: &BdevPassthruDeleteParams{Name: "Passthru0"}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateOcfBdev(t *testing.T) {
+	valid := OcfParams{Name: "Cache0", Mode: "wt", CacheLineSize: 16, CacheBdevName: "Malloc0", CoreBdevName: "Nvme0n1"}
+	tests := map[string]struct {
+		params   OcfParams
+		mock     *MockJSONRPC
+		want     string
+		wantCode codes.Code
+		wantErr  error
+	}{
+		"created": {
+			valid,
+			NewMockJSONRPC().On("bdev_ocf_create", `"Cache0"`),
+			"Cache0",
+			codes.OK,
+			nil,
+		},
+		"already exists": {
+			valid,
+			NewMockJSONRPC().OnError("bdev_ocf_create", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			codes.AlreadyExists,
+			ErrBdevExists,
+		},
+		"invalid mode": {
+			OcfParams{Name: "Cache0", Mode: "writeback", CacheBdevName: "Malloc0", CoreBdevName: "Nvme0n1"},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+		"no core bdev": {
+			OcfParams{Name: "Cache0", Mode: "wb", CacheBdevName: "Malloc0"},
+			NewMockJSONRPC(),
+			"",
+			codes.InvalidArgument,
+			nil,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).CreateOcfBdev(context.Background(), tt.params)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, &tt.params) {
+				t.Error("args: expected", &tt.params, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetOcfBdevs(t *testing.T) {
+	mock := NewMockJSONRPC().On("bdev_ocf_get_bdevs",
+		`[{"name":"Cache0","started":false,"cache":{"name":"Malloc0","attached":true},"core":{"name":"Nvme0n1","attached":false}}]`)
+	got, err := NewBdevService(mock).GetOcfBdevs(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []OcfBdev{{
+		Name:  "Cache0",
+		Cache: OcfBaseBdev{Name: "Malloc0", Attached: true},
+		Core:  OcfBaseBdev{Name: "Nvme0n1"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("bdevs: expected", want, "received", got)
+	}
+}
+
+func TestBdevService_GetOcfStats(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    OcfStats
+		wantErr error
+	}{
+		"stats": {
+			NewMockJSONRPC().On("bdev_ocf_get_stats",
+				`{"requests":{"rd_hits":{"count":3,"percentage":"75.0","units":"Requests"},"rd_total":{"count":4,"percentage":"100.0","units":"Requests"}}}`),
+			OcfStats{Requests: map[string]OcfStat{
+				"rd_hits":  {Count: 3, Percentage: "75.0", Units: "Requests"},
+				"rd_total": {Count: 4, Percentage: "100.0", Units: "Requests"},
+			}},
+			nil,
+		},
+		"not found": {
+			NewMockJSONRPC().OnError("bdev_ocf_get_stats", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			OcfStats{},
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).GetOcfStats(context.Background(), "Cache0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("stats: expected", tt.want, "received", got)
+			}
+			want := []MockCall{{Method: "bdev_ocf_get_stats", Args: &BdevOcfGetStatsParams{Name: "Cache0"}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_DeleteOcfBdev(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"deleted": {
+			NewMockJSONRPC().On("bdev_ocf_delete", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_ocf_delete", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+		"already deleted": {
+			NewMockJSONRPC().OnError("bdev_ocf_delete", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewBdevService(tt.mock).DeleteOcfBdev(context.Background(), "Cache0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := []MockCall{{Method: "bdev_ocf_delete", Args: &BdevOcfDeleteParams{Name: "Cache0"}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetNvmeControllers(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		mock     *MockJSONRPC
+		want     []NvmeController
+		wantArgs interface{}
+		wantErr  error
+	}{
+		"multipath": {
+			"",
+			NewMockJSONRPC().On("bdev_nvme_get_controllers", `[{"name":"Nvme0","ctrlrs":[`+
+				`{"state":"enabled","trid":{"trtype":"TCP","adrfam":"IPv4","traddr":"10.0.0.1","trsvcid":"4420","subnqn":"nqn.2016-06.io.spdk:cnode1"},"cntlid":1,"host":{"nqn":"nqn.host","addr":"","svcid":""}},`+
+				`{"state":"failed","trid":{"trtype":"TCP","adrfam":"IPv4","traddr":"10.0.0.2","trsvcid":"4420","subnqn":"nqn.2016-06.io.spdk:cnode1"},"cntlid":2,"host":{"nqn":"nqn.host","addr":"","svcid":""}}]}]`),
+			[]NvmeController{{
+				Name: "Nvme0",
+				Ctrlrs: []NvmeControllerPath{
+					{
+						State:  "enabled",
+						Trid:   NvmeTransportID{Trtype: "TCP", Adrfam: "IPv4", Traddr: "10.0.0.1", Trsvcid: "4420", Subnqn: "nqn.2016-06.io.spdk:cnode1"},
+						Cntlid: 1,
+						Host:   NvmeHost{Nqn: "nqn.host"},
+					},
+					{
+						State:  "failed",
+						Trid:   NvmeTransportID{Trtype: "TCP", Adrfam: "IPv4", Traddr: "10.0.0.2", Trsvcid: "4420", Subnqn: "nqn.2016-06.io.spdk:cnode1"},
+						Cntlid: 2,
+						Host:   NvmeHost{Nqn: "nqn.host"},
+					},
+				},
+			}},
+			nil,
+			nil,
+		},
+		"single controller": {
+			"Nvme1",
+			NewMockJSONRPC().On("bdev_nvme_get_controllers", `[{"name":"Nvme1","ctrlrs":[]}]`),
+			[]NvmeController{{Name: "Nvme1", Ctrlrs: []NvmeControllerPath{}}},
+			&BdevNvmeGetControllerParams{Name: "Nvme1"},
+			nil,
+		},
+		"not found": {
+			"Missing",
+			NewMockJSONRPC().OnError("bdev_nvme_get_controllers", &RPCError{Method: "bdev_nvme_get_controllers", Code: ENODEVCode, Message: "No such device"}),
+			nil,
+			&BdevNvmeGetControllerParams{Name: "Missing"},
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewBdevService(tt.mock).GetNvmeControllers(context.Background(), tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_AttachNvmeController(t *testing.T) {
+	tests := map[string]struct {
+		trtype    string
+		mock      *MockJSONRPC
+		want      []string
+		wantErr   error
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		"attached": {
+			"TCP",
+			NewMockJSONRPC().On("bdev_nvme_attach_controller", `["Nvme0n1","Nvme0n2"]`),
+			[]string{"Nvme0n1", "Nvme0n2"},
+			nil,
+			codes.OK,
+			1,
+		},
+		"already attached": {
+			"tcp",
+			NewMockJSONRPC().OnError("bdev_nvme_attach_controller", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			nil,
+			ErrNvmeControllerExists,
+			codes.AlreadyExists,
+			1,
+		},
+		"transport failure": {
+			"TCP",
+			NewMockJSONRPC().OnError("bdev_nvme_attach_controller", &RPCError{Code: EIOCode, Message: "Input/output error"}),
+			nil,
+			nil,
+			codes.Internal,
+			1,
+		},
+		"invalid trtype": {
+			"iSCSI",
+			NewMockJSONRPC(),
+			nil,
+			nil,
+			codes.InvalidArgument,
+			0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := NvmeAttachParams{Name: "Nvme0", Trtype: tt.trtype, Traddr: "127.0.0.1", Adrfam: "IPv4", Trsvcid: "4420", Subnqn: "nqn.2016-06.io.spdk:cnode1"}
+			got, err := NewBdevService(tt.mock).AttachNvmeController(context.Background(), params)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if errors.Is(err, ErrNvmeControllerExists) != errors.Is(tt.wantErr, ErrNvmeControllerExists) {
+				t.Error("error: unexpected", err)
+			}
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			if calls := len(tt.mock.Calls()); calls != tt.wantCalls {
+				t.Error("calls: expected", tt.wantCalls, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_DetachNvmeController(t *testing.T) {
+	tests := map[string]struct {
+		addr     *NvmfListenAddress
+		wantArgs *NvmeDetachParams
+	}{
+		"whole controller": {
+			nil,
+			&NvmeDetachParams{Name: "Nvme0"},
+		},
+		"single path": {
+			&NvmfListenAddress{Trtype: "TCP", Traddr: "127.0.0.1", Adrfam: "IPv4", Trsvcid: "4421"},
+			&NvmeDetachParams{Name: "Nvme0", Trtype: "TCP", Traddr: "127.0.0.1", Adrfam: "IPv4", Trsvcid: "4421"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("bdev_nvme_detach_controller", true)
+			if err := NewBdevService(mock).DetachNvmeController(context.Background(), "Nvme0", tt.addr); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if args := mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestBdevService_CreateRaidBdev(t *testing.T) {
+	tests := map[string]struct {
+		params    RaidParams
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		"created": {
+			RaidParams{Name: "Raid0", RaidLevel: "raid0", BaseBdevs: []string{"Malloc0", "Malloc1"}, StripSizeKb: 64},
+			codes.OK,
+			1,
+		},
+		"invalid level": {
+			RaidParams{Name: "Raid0", RaidLevel: "raid6", BaseBdevs: []string{"Malloc0", "Malloc1"}},
+			codes.InvalidArgument,
+			0,
+		},
+		"no base bdevs": {
+			RaidParams{Name: "Raid0", RaidLevel: "concat"},
+			codes.InvalidArgument,
+			0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("bdev_raid_create", true)
+			err := NewBdevService(mock).CreateRaidBdev(context.Background(), tt.params)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", code)
+			}
+			if calls := len(mock.Calls()); calls != tt.wantCalls {
+				t.Error("calls: expected", tt.wantCalls, "received", calls)
+			}
+		})
+	}
+}
+
+func TestBdevService_GetRaidBdevs(t *testing.T) {
+	tests := map[string]struct {
+		response string
+		want     []string
+	}{
+		"names": {
+			`["Raid0","Raid1"]`,
+			[]string{"Raid0", "Raid1"},
+		},
+		"objects": {
+			`[{"name":"Raid0","raid_level":"raid0","state":"online"},{"name":"Raid1","raid_level":"raid1","state":"online"}]`,
+			[]string{"Raid0", "Raid1"},
+		},
+		"empty": {
+			`[]`,
+			[]string{},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("bdev_raid_get_bdevs", tt.response)
+			got, err := NewBdevService(mock).GetRaidBdevs(context.Background(), "online")
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+
+	if _, err := NewBdevService(NewMockJSONRPC()).GetRaidBdevs(context.Background(), "broken"); status.Code(err) != codes.InvalidArgument {
+		t.Error("code: expected InvalidArgument received", err)
+	}
+}
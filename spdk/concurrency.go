@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+
+	"google.golang.org/grpc/status"
+)
+
+// acquire takes one of the in-flight slots configured with WithMaxConcurrency,
+// waiting until one frees up or ctx is done. The returned func gives it back.
+func (r *Client) acquire(ctx context.Context) (func(), error) {
+	if r.inflight == nil {
+		return func() {}, nil
+	}
+	select {
+	case r.inflight <- struct{}{}:
+		return func() { <-r.inflight }, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
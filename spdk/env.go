@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultSocket is where SPDK listens for RPCs unless told otherwise
+	DefaultSocket = "/var/tmp/spdk.sock"
+	// SocketEnv names the environment variable NewClientFromEnv reads the socket from
+	SocketEnv = "SPDK_RPC_SOCKET"
+	// TransportEnv names the environment variable that forces the transport
+	// of the socket, one of unix, tcp or tcp6
+	TransportEnv = "SPDK_RPC_TRANSPORT"
+)
+
+// NewClientFromEnv creates a client for the socket named by SPDK_RPC_SOCKET,
+// or DefaultSocket when it is unset, in which case the socket must exist.
+// SPDK_RPC_TRANSPORT forces the transport, otherwise it is detected as by NewClient.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	socket := os.Getenv(SocketEnv)
+	if socket == "" {
+		if _, err := os.Stat(DefaultSocket); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s is not set and %s is not usable: %v", SocketEnv, DefaultSocket, err)
+		}
+		socket = DefaultSocket
+	}
+	if transport := os.Getenv(TransportEnv); transport != "" {
+		if !containsFold(addressSchemes, transport) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q, expected one of %v", TransportEnv, transport, addressSchemes)
+		}
+		if !strings.Contains(socket, "://") {
+			socket = strings.ToLower(transport) + "://" + socket
+		}
+	}
+	return NewClientE(socket, opts...)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"os"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_NewClientFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		socket    string
+		transport string
+		wantProto string
+		wantAddr  string
+		wantCode  codes.Code
+	}{
+		"unix socket": {
+			"/run/spdk/spdk.sock",
+			"",
+			"unix",
+			"/run/spdk/spdk.sock",
+			codes.OK,
+		},
+		"detected tcp": {
+			"10.1.1.2:5260",
+			"",
+			"tcp",
+			"10.1.1.2:5260",
+			codes.OK,
+		},
+		"forced transport": {
+			"[fe80::1]:5260",
+			"TCP6",
+			"tcp6",
+			"[fe80::1]:5260",
+			codes.OK,
+		},
+		"invalid transport": {
+			"10.1.1.2:5260",
+			"udp",
+			"",
+			"",
+			codes.InvalidArgument,
+		},
+		"forced tcp without port": {
+			"10.1.1.2",
+			"tcp",
+			"",
+			"",
+			codes.InvalidArgument,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(SocketEnv, tt.socket)
+			t.Setenv(TransportEnv, tt.transport)
+			client, err := NewClientFromEnv(WithLogger(NopLogger{}))
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatal("code: expected", tt.wantCode, "received", code, err)
+			}
+			if err == nil && (client.Transport() != tt.wantProto || client.Socket() != tt.wantAddr) {
+				t.Error("response: expected", tt.wantProto, tt.wantAddr, "received", client.Transport(), client.Socket())
+			}
+		})
+	}
+}
+
+func TestSpdk_NewClientFromEnvDefault(t *testing.T) {
+	t.Setenv(SocketEnv, "")
+	t.Setenv(TransportEnv, "")
+	client, err := NewClientFromEnv(WithLogger(NopLogger{}))
+	if _, statErr := os.Stat(DefaultSocket); statErr != nil {
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Error("code: expected FailedPrecondition received", err)
+		}
+		return
+	}
+	if err != nil || client.Socket() != DefaultSocket {
+		t.Error("response: expected", DefaultSocket, "received", client, err)
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"errors"
+)
+
+var (
+	// ErrBdevNotFound indicates that SPDK has no block device with the requested name
+	ErrBdevNotFound = errors.New("bdev not found")
+	// ErrBdevExists indicates that a block device with the requested name already exists
+	ErrBdevExists = errors.New("bdev already exists")
+	// ErrNvmfSubsystemExists indicates that an NVMe-oF subsystem with the requested NQN already exists
+	ErrNvmfSubsystemExists = errors.New("nvmf subsystem already exists")
+	// ErrNvmfNamespaceExists indicates that the requested NSID is already in use in the NVMe-oF subsystem
+	ErrNvmfNamespaceExists = errors.New("nvmf namespace already exists")
+	// ErrNvmfTransportExists indicates that an NVMe-oF transport of the requested type already exists
+	ErrNvmfTransportExists = errors.New("nvmf transport already exists")
+	// ErrLvstoreExists indicates that the requested lvol store name is taken
+	ErrLvstoreExists = errors.New("lvol store already exists")
+	// ErrBdevClaimed indicates that the base bdev is already claimed, e.g. by
+	// another lvol store
+	ErrBdevClaimed = errors.New("bdev already claimed")
+	// ErrLvolExists indicates that the requested lvol, snapshot or clone name is taken in the lvol store
+	ErrLvolExists = errors.New("lvol already exists")
+	// ErrVhostControllerExists indicates that a vhost controller with the requested name already exists
+	ErrVhostControllerExists = errors.New("vhost controller already exists")
+	// ErrNvmeControllerExists indicates that an NVMe controller with the requested name,
+	// or the same path to the controller, is already attached
+	ErrNvmeControllerExists = errors.New("nvme controller already exists")
+	// ErrIscsiTargetNodeExists indicates that an iSCSI target node with the requested name already exists
+	ErrIscsiTargetNodeExists = errors.New("iscsi target node already exists")
+	// ErrNbdDeviceBusy indicates that the requested NBD device is already in use, e.g.
+	// it exports another bdev or no free NBD device was left to pick
+	ErrNbdDeviceBusy = errors.New("nbd device busy")
+)
+
+// sentinelError attaches a sentinel to the error it was derived from, so that
+// errors.Is matches the sentinel while errors.As still finds the *RPCError
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+// Error returns the sentinel message followed by the original error
+func (e *sentinelError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+// Unwrap returns the original error
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is the attached sentinel
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// wrapRPCError attaches sentinel to err when err is an *RPCError carrying one
+// of the given codes, any other error is returned unchanged
+func wrapRPCError(err error, sentinel error, codes ...int) error {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	for _, code := range codes {
+		if rpcErr.Code == code {
+			return &sentinelError{sentinel: sentinel, err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Failover is a JSONRPC that sends every call to the active SPDK endpoint and,
+// when it cannot be reached, moves on to the next one in the list and repeats
+// the call there. The endpoint that last answered stays active.
+type Failover struct {
+	clients []*Client
+	active  uint32
+}
+
+// build time check that struct implements interface
+var _ JSONRPC = (*Failover)(nil)
+
+// build time check that struct implements interface
+var _ Endpoint = (*Failover)(nil)
+
+// NewFailover creates a client for every socket, in order of preference,
+// all sharing the same options
+func NewFailover(sockets []string, opts ...Option) (*Failover, error) {
+	if len(sockets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one socket is required")
+	}
+	clients := make([]*Client, len(sockets))
+	for i, socket := range sockets {
+		client, err := NewClientE(socket, opts...)
+		if err != nil {
+			return nil, err
+		}
+		clients[i] = client
+	}
+	return &Failover{clients: clients}, nil
+}
+
+// Active returns the client currently receiving calls
+func (f *Failover) Active() *Client {
+	return f.clients[atomic.LoadUint32(&f.active)]
+}
+
+// Transport returns the network of the active endpoint
+func (f *Failover) Transport() string {
+	return f.Active().Transport()
+}
+
+// Socket returns the address of the active endpoint
+func (f *Failover) Socket() string {
+	return f.Active().Socket()
+}
+
+// GetID returns the id of the last request sent to the active endpoint
+func (f *Failover) GetID() uint64 {
+	return f.Active().GetID()
+}
+
+// GetVersion asks the first reachable endpoint for its version
+func (f *Failover) GetVersion(ctx context.Context) (SpdkVersion, error) {
+	var ver SpdkVersion
+	if err := f.Call(ctx, "spdk_get_version", nil, &ver); err != nil {
+		return SpdkVersion{}, err
+	}
+	return ver, nil
+}
+
+// StartUnixListener starts a listener on the active endpoint, for tests
+func (f *Failover) StartUnixListener() net.Listener {
+	return f.Active().StartUnixListener()
+}
+
+// Call sends the request to the active endpoint and fails over to the next
+// ones while they are unavailable. Errors returned by SPDK itself are
+// deterministic and returned as is.
+func (f *Failover) Call(ctx context.Context, method string, args, result interface{}) error {
+	start := atomic.LoadUint32(&f.active)
+	n := uint32(len(f.clients))
+	var err error
+	for i := uint32(0); i < n; i++ {
+		index := (start + i) % n
+		client := f.clients[index]
+		err = client.Call(ctx, method, args, result)
+		if !isUnreachable(err) || ctx.Err() != nil {
+			// an endpoint that answers, even with an error, is the one to use
+			if !isUnreachable(err) && index != start {
+				atomic.CompareAndSwapUint32(&f.active, start, index)
+			}
+			return err
+		}
+		if i+1 < n {
+			client.logger.Printf("Failing over from %s to %s after: %v", client.socket, f.clients[(index+1)%n].socket, err)
+		}
+	}
+	return err
+}
+
+// Connect makes the first endpoint, starting from the active one, that
+// accepts a connection the active endpoint. It fails only when none does.
+func (f *Failover) Connect(ctx context.Context) error {
+	start := atomic.LoadUint32(&f.active)
+	n := uint32(len(f.clients))
+	var err error
+	for i := uint32(0); i < n; i++ {
+		index := (start + i) % n
+		if err = f.clients[index].Connect(ctx); err == nil {
+			atomic.CompareAndSwapUint32(&f.active, start, index)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Close releases the persistent connections of every endpoint
+func (f *Failover) Close() error {
+	var first error
+	for _, client := range f.clients {
+		if err := client.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// isUnreachable reports whether err means SPDK could not be reached at all,
+// as opposed to SPDK answering with an error. A connection lost after the
+// request was written is codes.Aborted and does not count, SPDK may have run
+// the request and the standby must not run it again.
+func isUnreachable(err error) bool {
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		return false
+	}
+	return status.Code(err) == codes.Unavailable
+}
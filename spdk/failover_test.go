@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_Failover(t *testing.T) {
+	dir := t.TempDir()
+	primary, standby := filepath.Join(dir, "primary.sock"), filepath.Join(dir, "standby.sock")
+	failover, err := NewFailover([]string{primary, standby}, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if failover.Socket() != primary {
+		t.Error("active: expected", primary, "received", failover.Socket())
+	}
+
+	// only the standby is up
+	ln, err := net.Listen("unix", standby)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer ln.Close()
+	serve(ln, func(req RPCRequest) string {
+		if req.Method == "bdev_get_bdevs" {
+			return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-19,"message":"No such device"}}`, req.ID)
+		}
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.01"}}`, req.ID)
+	})
+
+	ctx := context.Background()
+	ver, err := failover.GetVersion(ctx)
+	if err != nil || ver.Version != "SPDK v23.01" {
+		t.Fatal("unexpected response", ver, err)
+	}
+	if failover.Socket() != standby {
+		t.Error("active: expected", standby, "received", failover.Socket())
+	}
+
+	// SPDK errors come back as they are without moving on
+	var rpcErr *RPCError
+	if err := failover.Call(ctx, "bdev_get_bdevs", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != ENODEVCode {
+		t.Error("error: expected ENODEV received", err)
+	}
+	if failover.Socket() != standby {
+		t.Error("active: expected", standby, "received", failover.Socket())
+	}
+
+	// nothing is reachable
+	_ = ln.Close()
+	if err := failover.Call(ctx, "spdk_get_version", nil, nil); status.Code(err) != codes.Unavailable {
+		t.Error("code: expected Unavailable received", err)
+	}
+}
+
+func TestSpdk_FailoverToFailingStandby(t *testing.T) {
+	dir := t.TempDir()
+	primary, standby := filepath.Join(dir, "primary.sock"), filepath.Join(dir, "standby.sock")
+	failover, err := NewFailover([]string{primary, standby}, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	ln, err := net.Listen("unix", standby)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer ln.Close()
+	serve(ln, func(req RPCRequest) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-19,"message":"No such device"}}`, req.ID)
+	})
+
+	// the standby answered, with an error, so it stays the active endpoint
+	var rpcErr *RPCError
+	if err := failover.Call(context.Background(), "bdev_get_bdevs", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != ENODEVCode {
+		t.Error("error: expected ENODEV received", err)
+	}
+	if failover.Socket() != standby {
+		t.Error("active: expected", standby, "received", failover.Socket())
+	}
+}
+
+func TestSpdk_FailoverAfterSend(t *testing.T) {
+	dir := t.TempDir()
+	primary, standby := filepath.Join(dir, "primary.sock"), filepath.Join(dir, "standby.sock")
+	failover, err := NewFailover([]string{primary, standby}, WithLogger(NopLogger{}), WithMultiplexing())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer failover.Close()
+	// the primary reads and runs the request, then drops the connection mid-response
+	lnPrimary, err := net.Listen("unix", primary)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer lnPrimary.Close()
+	go func() {
+		conn, err := lnPrimary.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var req RPCRequest
+		if err := json.NewDecoder(conn).Decode(&req); err == nil {
+			_, _ = io.WriteString(conn, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"res`, req.ID))
+		}
+	}()
+	lnStandby, err := net.Listen("unix", standby)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer lnStandby.Close()
+	var standbyCalls int32
+	serve(lnStandby, func(req RPCRequest) string {
+		atomic.AddInt32(&standbyCalls, 1)
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"Malloc0"}`, req.ID)
+	})
+
+	var name string
+	if err := failover.Call(context.Background(), "bdev_malloc_create", nil, &name); status.Code(err) != codes.Aborted {
+		t.Error("code: expected", codes.Aborted, "received", err)
+	}
+	if n := atomic.LoadInt32(&standbyCalls); n != 0 {
+		t.Error("standby: expected no replay received", n)
+	}
+	if failover.Socket() != primary {
+		t.Error("active: expected", primary, "received", failover.Socket())
+	}
+}
+
+func TestSpdk_NewFailover(t *testing.T) {
+	if _, err := NewFailover(nil); status.Code(err) != codes.InvalidArgument {
+		t.Error("no sockets: expected InvalidArgument received", err)
+	}
+	if _, err := NewFailover([]string{"/var/tmp/spdk.sock", ""}); status.Code(err) != codes.InvalidArgument {
+		t.Error("empty socket: expected InvalidArgument received", err)
+	}
+}
+
+func TestSpdk_FailoverConnect(t *testing.T) {
+	dir := t.TempDir()
+	primary, standby := filepath.Join(dir, "primary.sock"), filepath.Join(dir, "standby.sock")
+	failover, err := NewFailover([]string{primary, standby}, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	ctx := context.Background()
+	if err := failover.Connect(ctx); status.Code(err) != codes.Unavailable {
+		t.Error("code: expected", codes.Unavailable, "received", err)
+	}
+
+	ln, err := net.Listen("unix", standby)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer ln.Close()
+	if err := failover.Connect(ctx); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if failover.Socket() != standby {
+		t.Error("active: expected", standby, "received", failover.Socket())
+	}
+}
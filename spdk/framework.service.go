@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// FrameworkService is interface to all application framework functions in spdk
+type FrameworkService interface {
+	Shutdown(ctx context.Context) error
+	StartFramework(ctx context.Context) error
+	SetMonitorContextSwitch(ctx context.Context, enabled bool) (bool, error)
+
+	GetThreadStats(ctx context.Context) (ThreadStats, error)
+	GetReactorUtilization(ctx context.Context) (ReactorStats, error)
+
+	SetScheduler(ctx context.Context, name string, period uint64) error
+	GetScheduler(ctx context.Context) (SchedulerInfo, error)
+
+	GetSubsystems(ctx context.Context) ([]Subsystem, error)
+	GetSubsystemConfig(ctx context.Context, name string) (json.RawMessage, error)
+
+	SaveConfig(ctx context.Context) (json.RawMessage, error)
+	LoadConfig(ctx context.Context, config json.RawMessage) error
+}
@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// schedulerNames lists the schedulers framework_set_scheduler can switch to
+var schedulerNames = []string{"static", "dynamic", "gscheduler"}
+
+// FrameworkServiceImpl implements FrameworkService interface
+type FrameworkServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ FrameworkService = (*FrameworkServiceImpl)(nil)
+
+// NewFrameworkService is a constructor for FrameworkServiceImpl
+func NewFrameworkService(client JSONRPC) *FrameworkServiceImpl {
+	return &FrameworkServiceImpl{client}
+}
+
+// Shutdown asks SPDK to stop cleanly with SIGTERM. SPDK may close the socket
+// before it gets to reply, which counts as success here.
+func (p *FrameworkServiceImpl) Shutdown(ctx context.Context) error {
+	params := SpdkKillInstanceParams{
+		SigName: "SIGTERM",
+	}
+	var result SpdkKillInstanceResult
+	err := p.client.Call(ctx, "spdk_kill_instance", &params, &result)
+	if errors.Is(err, ErrEmptyResponse) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		log.Print("Could not shut down SPDK")
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// StartFramework initializes the subsystems of an SPDK started with
+// --wait-for-rpc, after which only runtime methods are allowed. SPDK rejects
+// the call once the framework is initialized.
+func (p *FrameworkServiceImpl) StartFramework(ctx context.Context) error {
+	var result FrameworkStartInitResult
+	err := p.client.Call(ctx, "framework_start_init", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		log.Print("Could not start SPDK framework initialization")
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// SetMonitorContextSwitch enables or disables monitoring the context switches
+// of the reactors and returns whether monitoring is enabled afterwards
+func (p *FrameworkServiceImpl) SetMonitorContextSwitch(ctx context.Context, enabled bool) (bool, error) {
+	params := FrameworkMonitorContextSwitchParams{
+		Enabled: enabled,
+	}
+	var result FrameworkMonitorContextSwitchResult
+	err := p.client.Call(ctx, "framework_monitor_context_switch", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return false, err
+	}
+	return result.Enabled, nil
+}
+
+// GetThreadStats returns the busy and idle ticks of every SPDK thread, see
+// BusyPercent to turn two samples into a utilization
+func (p *FrameworkServiceImpl) GetThreadStats(ctx context.Context) (ThreadStats, error) {
+	var result ThreadStats
+	err := p.client.Call(ctx, "thread_get_stats", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return ThreadStats{}, err
+	}
+	return result, nil
+}
+
+// GetReactorUtilization returns the busy and idle ticks of every reactor,
+// one per core, together with the threads currently assigned to it
+func (p *FrameworkServiceImpl) GetReactorUtilization(ctx context.Context) (ReactorStats, error) {
+	var result ReactorStats
+	err := p.client.Call(ctx, "framework_get_reactors", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return ReactorStats{}, err
+	}
+	return result, nil
+}
+
+// SetScheduler switches the reactors to the named scheduler, one of static,
+// dynamic or gscheduler, rebalancing threads every period microseconds. A
+// zero period keeps the current one.
+func (p *FrameworkServiceImpl) SetScheduler(ctx context.Context, name string, period uint64) error {
+	if !containsFold(schedulerNames, name) {
+		return status.Errorf(codes.InvalidArgument, "invalid scheduler %q, expected one of %v", name, schedulerNames)
+	}
+	params := FrameworkSetSchedulerParams{
+		Name:   name,
+		Period: period,
+	}
+	var result FrameworkSetSchedulerResult
+	err := p.client.Call(ctx, "framework_set_scheduler", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not set SPDK scheduler: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetScheduler returns the scheduler in use with its period and governor
+func (p *FrameworkServiceImpl) GetScheduler(ctx context.Context) (SchedulerInfo, error) {
+	var result SchedulerInfo
+	err := p.client.Call(ctx, "framework_get_scheduler", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return SchedulerInfo{}, err
+	}
+	return result, nil
+}
+
+// GetSubsystems lists the framework subsystems in initialization order
+func (p *FrameworkServiceImpl) GetSubsystems(ctx context.Context) ([]Subsystem, error) {
+	var result []Subsystem
+	err := p.client.Call(ctx, "framework_get_subsystems", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubsystemConfig returns the configuration of a single subsystem as the
+// list of calls that recreate it, an unknown subsystem is reported as an
+// invalid parameters error by SPDK
+func (p *FrameworkServiceImpl) GetSubsystemConfig(ctx context.Context, name string) (json.RawMessage, error) {
+	params := FrameworkGetConfigParams{
+		Name: name,
+	}
+	var result json.RawMessage
+	err := p.client.Call(ctx, "framework_get_config", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveConfig returns the configuration of all subsystems in the format of the
+// SPDK JSON config file. SPDK has no single RPC for it, so like rpc.py it is
+// assembled from GetSubsystems and GetSubsystemConfig.
+func (p *FrameworkServiceImpl) SaveConfig(ctx context.Context) (json.RawMessage, error) {
+	subsystems, err := p.GetSubsystems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config := SpdkConfig{
+		Subsystems: make([]SubsystemConfig, 0, len(subsystems)),
+	}
+	for _, subsystem := range subsystems {
+		result, err := p.GetSubsystemConfig(ctx, subsystem.Subsystem)
+		if err != nil {
+			return nil, err
+		}
+		config.Subsystems = append(config.Subsystems, SubsystemConfig{
+			Subsystem: subsystem.Subsystem,
+			Config:    result,
+		})
+	}
+	return json.Marshal(&config)
+}
+
+// LoadConfig replays a configuration saved by SaveConfig the way rpc.py
+// load_config does: every entry is sent once SPDK allows its method in the
+// current state, and framework_start_init is called when only runtime
+// methods are left.
+func (p *FrameworkServiceImpl) LoadConfig(ctx context.Context, config json.RawMessage) error {
+	var saved struct {
+		Subsystems []struct {
+			Subsystem string        `json:"subsystem"`
+			Config    []ConfigEntry `json:"config"`
+		} `json:"subsystems"`
+	}
+	if err := json.Unmarshal(config, &saved); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid config: %v", err)
+	}
+	var pending []ConfigEntry
+	for _, subsystem := range saved.Subsystems {
+		pending = append(pending, subsystem.Config...)
+	}
+	for len(pending) != 0 {
+		allowed, err := p.currentMethods(ctx)
+		if err != nil {
+			return err
+		}
+		var remaining []ConfigEntry
+		for _, entry := range pending {
+			if _, ok := allowed[entry.Method]; !ok {
+				remaining = append(remaining, entry)
+				continue
+			}
+			var params interface{}
+			if len(entry.Params) != 0 {
+				params = entry.Params
+			}
+			if err := p.client.Call(ctx, entry.Method, params, nil); err != nil {
+				log.Printf("error: %v", err)
+				return err
+			}
+		}
+		if len(remaining) == 0 {
+			break
+		}
+		if _, ok := allowed["framework_start_init"]; ok {
+			if err := p.StartFramework(ctx); err != nil {
+				return err
+			}
+		} else if len(remaining) == len(pending) {
+			methods := make([]string, 0, len(remaining))
+			for _, entry := range remaining {
+				methods = append(methods, entry.Method)
+			}
+			return status.Errorf(codes.FailedPrecondition, "methods not allowed in the current state: %s", strings.Join(methods, ", "))
+		}
+		pending = remaining
+	}
+	return nil
+}
+
+// currentMethods returns the set of methods SPDK allows in its current state
+func (p *FrameworkServiceImpl) currentMethods(ctx context.Context) (map[string]struct{}, error) {
+	var methods []string
+	err := p.client.Call(ctx, "rpc_get_methods", &RPCGetMethodsParams{Current: true}, &methods)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	allowed := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		allowed[method] = struct{}{}
+	}
+	return allowed, nil
+}
@@ -0,0 +1,395 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFrameworkService_Shutdown(t *testing.T) {
+	tests := map[string]struct {
+		options []Option
+		reply   bool
+	}{
+		"replied": {
+			nil,
+			true,
+		},
+		"closed without reply": {
+			nil,
+			false,
+		},
+		"persistent closed without reply": {
+			[]Option{WithPersistentConnection()},
+			false,
+		},
+		"multiplexed closed without reply": {
+			[]Option{WithMultiplexing()},
+			false,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), append(tt.options, WithLogger(NopLogger{}))...)
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			defer client.Close()
+			received := make(chan RPCRequest, 1)
+			reply := tt.reply
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				var req RPCRequest
+				if err := json.NewDecoder(conn).Decode(&req); err != nil {
+					return
+				}
+				received <- req
+				if reply {
+					_, _ = fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+				}
+			}()
+
+			if err := NewFrameworkService(client).Shutdown(context.Background()); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			req := <-received
+			if req.Method != "spdk_kill_instance" || !reflect.DeepEqual(req.Params, map[string]interface{}{"sig_name": "SIGTERM"}) {
+				t.Error("request: unexpected", req.Method, req.Params)
+			}
+		})
+	}
+}
+
+func TestFrameworkService_ShutdownError(t *testing.T) {
+	rpcErr := &RPCError{Code: InvalidParamsCode, Message: "Invalid parameters"}
+	mock := NewMockJSONRPC().OnError("spdk_kill_instance", rpcErr)
+	if err := NewFrameworkService(mock).Shutdown(context.Background()); !errors.Is(err, rpcErr) {
+		t.Error("error: expected", rpcErr, "received", err)
+	}
+}
+
+func TestFrameworkService_StartFramework(t *testing.T) {
+	initialized := &RPCError{Code: EPERMCode, Message: "Method may only be called during startup"}
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"started": {
+			NewMockJSONRPC().On("framework_start_init", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("framework_start_init", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+		"already initialized": {
+			NewMockJSONRPC().OnError("framework_start_init", initialized),
+			initialized,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewFrameworkService(tt.mock).StartFramework(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+		})
+	}
+}
+
+func TestFrameworkService_SetMonitorContextSwitch(t *testing.T) {
+	mock := NewMockJSONRPC().On("framework_monitor_context_switch", `{"enabled":false}`)
+	got, err := NewFrameworkService(mock).SetMonitorContextSwitch(context.Background(), false)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if got {
+		t.Error("enabled: expected false received", got)
+	}
+	want := []MockCall{{Method: "framework_monitor_context_switch", Args: &FrameworkMonitorContextSwitchParams{Enabled: false}}}
+	if calls := mock.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Error("calls: expected", want, "received", calls)
+	}
+}
+
+func TestFrameworkService_SetScheduler(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		period   uint64
+		mock     *MockJSONRPC
+		wantArgs interface{}
+		wantCode codes.Code
+	}{
+		"dynamic": {
+			"dynamic",
+			1000000,
+			NewMockJSONRPC().On("framework_set_scheduler", true),
+			&FrameworkSetSchedulerParams{Name: "dynamic", Period: 1000000},
+			codes.OK,
+		},
+		"keep period": {
+			"static",
+			0,
+			NewMockJSONRPC().On("framework_set_scheduler", true),
+			&FrameworkSetSchedulerParams{Name: "static"},
+			codes.OK,
+		},
+		"unexpected result": {
+			"gscheduler",
+			0,
+			NewMockJSONRPC().On("framework_set_scheduler", false),
+			&FrameworkSetSchedulerParams{Name: "gscheduler"},
+			codes.FailedPrecondition,
+		},
+		"unknown scheduler": {
+			"roundrobin",
+			0,
+			NewMockJSONRPC(),
+			nil,
+			codes.InvalidArgument,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewFrameworkService(tt.mock).SetScheduler(context.Background(), tt.name, tt.period)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantArgs == nil {
+				if len(tt.mock.Calls()) != 0 {
+					t.Error("calls: expected none received", tt.mock.Calls())
+				}
+				return
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Error("args: expected", tt.wantArgs, "received", args)
+			}
+		})
+	}
+}
+
+func TestFrameworkService_GetScheduler(t *testing.T) {
+	mock := NewMockJSONRPC().On("framework_get_scheduler",
+		`{"scheduler_name":"dynamic","scheduler_period":1000000,"governor_name":"dpdk_governor","load_limit":20}`)
+	got, err := NewFrameworkService(mock).GetScheduler(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := SchedulerInfo{SchedulerName: "dynamic", SchedulerPeriod: 1000000, GovernorName: "dpdk_governor"}
+	if got != want {
+		t.Error("scheduler: expected", want, "received", got)
+	}
+}
+
+func TestFrameworkService_SaveConfig(t *testing.T) {
+	mock := NewMockJSONRPC().
+		On("framework_get_subsystems", `[{"subsystem":"accel","depends_on":[]},{"subsystem":"bdev","depends_on":["accel"]}]`).
+		On("framework_get_config", `[{"method":"bdev_set_options","params":{"bdev_io_pool_size":65535}}]`)
+	got, err := NewFrameworkService(mock).SaveConfig(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expected := `{"subsystems":[` +
+		`{"subsystem":"accel","config":[{"method":"bdev_set_options","params":{"bdev_io_pool_size":65535}}]},` +
+		`{"subsystem":"bdev","config":[{"method":"bdev_set_options","params":{"bdev_io_pool_size":65535}}]}]}`
+	if string(got) != expected {
+		t.Error("config: expected", expected, "received", string(got))
+	}
+	calls := mock.Calls()
+	if len(calls) != 3 || !reflect.DeepEqual(calls[2].Args, &FrameworkGetConfigParams{Name: "bdev"}) {
+		t.Error("calls: unexpected", calls)
+	}
+}
+
+func TestFrameworkService_LoadConfig(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		wantMethods []string
+		wantErr     bool
+	}{
+		"startup then runtime": {
+			`{"subsystems":[{"subsystem":"bdev","config":[` +
+				`{"method":"bdev_malloc_create","params":{"name":"Malloc0"}},` +
+				`{"method":"bdev_set_options","params":{"bdev_io_pool_size":65535}}]}]}`,
+			[]string{"bdev_set_options", "framework_start_init", "bdev_malloc_create"},
+			false,
+		},
+		"empty subsystem": {
+			`{"subsystems":[{"subsystem":"bdev","config":null}]}`,
+			nil,
+			false,
+		},
+		"unknown method": {
+			`{"subsystems":[{"subsystem":"bdev","config":[{"method":"bdev_unknown"}]}]}`,
+			[]string{"framework_start_init"},
+			true,
+		},
+		"invalid config": {
+			`[]`,
+			nil,
+			true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			var methods []string
+			initialized := false
+			serve(ln, func(req RPCRequest) string {
+				if req.Method == "rpc_get_methods" {
+					allowed := `["framework_start_init","bdev_set_options"]`
+					if initialized {
+						allowed = `["bdev_malloc_create"]`
+					}
+					return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, allowed)
+				}
+				methods = append(methods, req.Method)
+				if req.Method == "framework_start_init" {
+					initialized = true
+				}
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+			})
+
+			err := NewFrameworkService(client).LoadConfig(context.Background(), json.RawMessage(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(methods, tt.wantMethods) {
+				t.Error("methods: expected", tt.wantMethods, "received", methods)
+			}
+		})
+	}
+}
+
+func TestFrameworkService_GetSubsystems(t *testing.T) {
+	mock := NewMockJSONRPC().On("framework_get_subsystems", `[{"subsystem":"accel","depends_on":[]},{"subsystem":"bdev","depends_on":["accel","vmd"]}]`)
+	got, err := NewFrameworkService(mock).GetSubsystems(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []Subsystem{
+		{Subsystem: "accel", DependsOn: []string{}},
+		{Subsystem: "bdev", DependsOn: []string{"accel", "vmd"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestFrameworkService_GetSubsystemConfig(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    string
+		wantErr error
+	}{
+		"config": {
+			NewMockJSONRPC().On("framework_get_config", `[{"method":"iobuf_set_options","params":{"small_pool_count":8192}}]`),
+			`[{"method":"iobuf_set_options","params":{"small_pool_count":8192}}]`,
+			nil,
+		},
+		"unknown subsystem": {
+			NewMockJSONRPC().OnError("framework_get_config", &RPCError{Code: InvalidParamsCode, Message: "Subsystem not found"}),
+			"",
+			&RPCError{Code: InvalidParamsCode, Message: "Subsystem not found"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewFrameworkService(tt.mock).GetSubsystemConfig(context.Background(), "iobuf")
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if string(got) != tt.want {
+				t.Error("response: expected", tt.want, "received", string(got))
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, &FrameworkGetConfigParams{Name: "iobuf"}) {
+				t.Error("args: unexpected", args)
+			}
+		})
+	}
+}
+
+func TestFrameworkService_GetThreadStats(t *testing.T) {
+	mock := NewMockJSONRPC().On("thread_get_stats", `{"tick_rate":2300000000,"threads":[`+
+		`{"name":"app_thread","id":1,"cpumask":"1","busy":139223208,"idle":8641080608,"active_pollers_count":1,"timed_pollers_count":2,"paused_pollers_count":0}]}`)
+	got, err := NewFrameworkService(mock).GetThreadStats(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := ThreadStats{
+		TickRate: 2300000000,
+		Threads: []ThreadStat{{
+			Name: "app_thread", ID: 1, Cpumask: "1", Busy: 139223208, Idle: 8641080608,
+			ActivePollersCount: 1, TimedPollersCount: 2,
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestFrameworkService_GetReactorUtilization(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    ReactorStats
+		wantErr error
+	}{
+		"reactors": {
+			NewMockJSONRPC().On("framework_get_reactors", `{"tick_rate":2400000000,"reactors":[`+
+				`{"lcore":0,"busy":41289723495,"idle":3624832946,"in_interrupt":false,"lw_threads":[{"name":"app_thread","id":1,"cpumask":"1","elapsed":44910853363}]},`+
+				`{"lcore":1,"busy":0,"idle":4000000,"in_interrupt":true,"lw_threads":[]}]}`),
+			ReactorStats{
+				TickRate: 2400000000,
+				Reactors: []Reactor{
+					{Lcore: 0, Busy: 41289723495, Idle: 3624832946, LwThreads: []ReactorThread{{Name: "app_thread", ID: 1, Cpumask: "1", Elapsed: 44910853363}}},
+					{Lcore: 1, Idle: 4000000, InInterrupt: true, LwThreads: []ReactorThread{}},
+				},
+			},
+			nil,
+		},
+		"failed": {
+			NewMockJSONRPC().OnError("framework_get_reactors", ErrFailedSpdkCall),
+			ReactorStats{},
+			ErrFailedSpdkCall,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewFrameworkService(tt.mock).GetReactorUtilization(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
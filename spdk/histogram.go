@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxHistogramBucketShift bounds the bucket shift SPDK may report, beyond it
+// the buckets would not fit the 64 bit tick values they count
+const maxHistogramBucketShift = 32
+
+// HistogramBucket counts the I/Os whose latency was at least Start and less than End
+type HistogramBucket struct {
+	Start time.Duration
+	End   time.Duration
+	Count uint64
+}
+
+// Histogram is the latency histogram of a bdev since its histogram was
+// enabled, with only the buckets that counted an I/O in ascending order
+type Histogram struct {
+	TscRate     uint64
+	BucketShift uint32
+	Buckets     []HistogramBucket
+}
+
+// Total returns the number of I/Os counted by the histogram
+func (h *Histogram) Total() uint64 {
+	var total uint64
+	for _, b := range h.Buckets {
+		total += b.Count
+	}
+	return total
+}
+
+// Percentile returns the upper bound of the bucket holding the given
+// percentile, e.g. 99.9, of the I/O latencies, or 0 when nothing was counted
+func (h *Histogram) Percentile(p float64) time.Duration {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+	var seen uint64
+	for _, b := range h.Buckets {
+		seen += b.Count
+		if float64(seen)*100 >= p*float64(total) {
+			return b.End
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1].End
+}
+
+// decodeHistogram unpacks the base64 encoded bucket counters of SPDK's
+// spdk_histogram_data. There are 65-BucketShift ranges of 1<<BucketShift
+// buckets each, range 0 counts tick values below 1<<BucketShift one tick per
+// bucket and every further range doubles both its start and its bucket width.
+func decodeHistogram(result *BdevGetHistogramResult) (Histogram, error) {
+	if result.BucketShift == 0 || result.BucketShift > maxHistogramBucketShift {
+		return Histogram{}, status.Errorf(codes.Internal, "unsupported histogram bucket shift %d", result.BucketShift)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Histogram)
+	if err != nil {
+		return Histogram{}, status.Errorf(codes.Internal, "malformed histogram: %v", err)
+	}
+	shift := result.BucketShift
+	perRange := uint64(1) << shift
+	ranges := uint64(64 - shift + 1)
+	if uint64(len(data)) != ranges*perRange*8 {
+		return Histogram{}, status.Errorf(codes.Internal, "histogram has %d bytes, expected %d for bucket shift %d",
+			len(data), ranges*perRange*8, shift)
+	}
+	histogram := Histogram{TscRate: result.TscRate, BucketShift: shift}
+	for i := uint64(0); i < ranges*perRange; i++ {
+		// the counters are sent in the byte order of the SPDK host, which is
+		// little endian on every platform SPDK supports
+		count := binary.LittleEndian.Uint64(data[i*8:])
+		if count == 0 {
+			continue
+		}
+		rng, index := i/perRange, i%perRange
+		start, width := index, uint64(1)
+		if rng > 0 {
+			width = uint64(1) << (rng - 1)
+			start = uint64(1)<<(rng+uint64(shift)-1) + index*width
+		}
+		end := start + width
+		if end < start {
+			// the last bucket ends past the largest tick value
+			end = math.MaxUint64
+		}
+		histogram.Buckets = append(histogram.Buckets, HistogramBucket{
+			Start: ticksToDuration(start, result.TscRate),
+			End:   ticksToDuration(end, result.TscRate),
+			Count: count,
+		})
+	}
+	return histogram, nil
+}
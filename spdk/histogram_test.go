@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// encodeHistogram builds the base64 bucket counters SPDK sends for the given
+// bucket shift, counts maps a bucket index to its counter
+func encodeHistogram(shift uint32, counts map[int]uint64) string {
+	data := make([]byte, (64-shift+1)*(1<<shift)*8)
+	for i, count := range counts {
+		binary.LittleEndian.PutUint64(data[i*8:], count)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestHistogram_Decode(t *testing.T) {
+	tests := map[string]struct {
+		result   BdevGetHistogramResult
+		want     Histogram
+		wantCode codes.Code
+	}{
+		"empty": {
+			BdevGetHistogramResult{Histogram: encodeHistogram(7, nil), TscRate: 1000000000, BucketShift: 7},
+			Histogram{TscRate: 1000000000, BucketShift: 7},
+			codes.OK,
+		},
+		"buckets of every width": {
+			// range 0 bucket 5, range 1 bucket 0 and range 2 bucket 3
+			BdevGetHistogramResult{Histogram: encodeHistogram(7, map[int]uint64{5: 2, 128: 1, 259: 4}), TscRate: 1000000000, BucketShift: 7},
+			Histogram{TscRate: 1000000000, BucketShift: 7, Buckets: []HistogramBucket{
+				{Start: 5 * time.Nanosecond, End: 6 * time.Nanosecond, Count: 2},
+				{Start: 128 * time.Nanosecond, End: 129 * time.Nanosecond, Count: 1},
+				{Start: 262 * time.Nanosecond, End: 264 * time.Nanosecond, Count: 4},
+			}},
+			codes.OK,
+		},
+		"ticks in microseconds": {
+			BdevGetHistogramResult{Histogram: encodeHistogram(1, map[int]uint64{4: 1}), TscRate: 1000, BucketShift: 1},
+			Histogram{TscRate: 1000, BucketShift: 1, Buckets: []HistogramBucket{
+				{Start: 4 * time.Millisecond, End: 6 * time.Millisecond, Count: 1},
+			}},
+			codes.OK,
+		},
+		"wrong size": {
+			BdevGetHistogramResult{Histogram: encodeHistogram(6, nil), TscRate: 1000000000, BucketShift: 7},
+			Histogram{},
+			codes.Internal,
+		},
+		"not base64": {
+			BdevGetHistogramResult{Histogram: "!!", TscRate: 1000000000, BucketShift: 7},
+			Histogram{},
+			codes.Internal,
+		},
+		"no bucket shift": {
+			BdevGetHistogramResult{Histogram: "", TscRate: 1000000000},
+			Histogram{},
+			codes.Internal,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeHistogram(&tt.result)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("histogram: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := Histogram{Buckets: []HistogramBucket{
+		{Start: 0, End: time.Microsecond, Count: 90},
+		{Start: time.Microsecond, End: 2 * time.Microsecond, Count: 9},
+		{Start: time.Millisecond, End: 2 * time.Millisecond, Count: 1},
+	}}
+	if got := h.Total(); got != 100 {
+		t.Error("total: expected", 100, "received", got)
+	}
+	tests := map[float64]time.Duration{
+		50:  time.Microsecond,
+		90:  time.Microsecond,
+		99:  2 * time.Microsecond,
+		100: 2 * time.Millisecond,
+	}
+	for p, want := range tests {
+		if got := h.Percentile(p); got != want {
+			t.Error("percentile", p, ": expected", want, "received", got)
+		}
+	}
+	if got := (&Histogram{}).Percentile(99); got != 0 {
+		t.Error("empty: expected", 0, "received", got)
+	}
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// CallInterceptor wraps Call, e.g. to log, measure or restrict calls. It must
+// call next to continue the chain, and may skip it to fail the call early.
+type CallInterceptor func(ctx context.Context, method string, args, result interface{}, next func() error) error
+
+// intercept runs the interceptors from index i onwards around call
+func (r *Client) intercept(ctx context.Context, i int, method string, args, result interface{}) error {
+	if i == len(r.interceptors) {
+		return r.call(ctx, method, args, result)
+	}
+	return r.interceptors[i](ctx, method, args, result, func() error {
+		return r.intercept(ctx, i+1, method, args, result)
+	})
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// allowMethods is an example interceptor that only lets read-only methods through
+func allowMethods(prefixes ...string) CallInterceptor {
+	return func(ctx context.Context, method string, args, result interface{}, next func() error) error {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(method, prefix) {
+				return next()
+			}
+		}
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed", method)
+	}
+}
+
+func TestSpdk_WithInterceptors(t *testing.T) {
+	var order []string
+	record := func(name string) CallInterceptor {
+		return func(ctx context.Context, method string, args, result interface{}, next func() error) error {
+			order = append(order, name+" before "+method)
+			err := next()
+			order = append(order, name+" after "+method)
+			return err
+		}
+	}
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}),
+		WithInterceptors(record("outer"), allowMethods("spdk_get_", "bdev_get_")),
+		WithInterceptors(record("inner")))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	accepted := serve(ln, func(req RPCRequest) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.01"}}`, req.ID)
+	})
+
+	ctx := context.Background()
+	if _, err := client.GetVersion(ctx); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := client.Call(ctx, "bdev_malloc_delete", nil, nil); status.Code(err) != codes.PermissionDenied {
+		t.Error("code: expected PermissionDenied received", err)
+	}
+
+	expected := []string{
+		"outer before spdk_get_version",
+		"inner before spdk_get_version",
+		"inner after spdk_get_version",
+		"outer after spdk_get_version",
+		"outer before bdev_malloc_delete",
+		"outer after bdev_malloc_delete",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Error("order: expected", expected, "received", order)
+	}
+	if n := atomic.LoadInt32(accepted); n != 1 {
+		t.Error("connections: expected 1 received", n)
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TicksToDuration converts a tick count reported by SPDK to a duration using
+// the tick rate of the result, it returns 0 when the tick rate is unknown
+func (r *IostatResult) TicksToDuration(ticks uint64) time.Duration {
+	return ticksToDuration(ticks, uint64(r.TickRate))
+}
+
+// Uptime returns the time elapsed since SPDK started counting ticks
+func (r *IostatResult) Uptime() time.Duration {
+	return r.TicksToDuration(uint64(r.Ticks))
+}
+
+// ReadLatency returns the average latency of a read operation of the given bdev
+func (r *IostatResult) ReadLatency(b *BdevIostat) time.Duration {
+	return r.averageLatency(b.ReadLatencyTicks, b.NumReadOps)
+}
+
+// WriteLatency returns the average latency of a write operation of the given bdev
+func (r *IostatResult) WriteLatency(b *BdevIostat) time.Duration {
+	return r.averageLatency(b.WriteLatencyTicks, b.NumWriteOps)
+}
+
+// UnmapLatency returns the average latency of an unmap operation of the given bdev
+func (r *IostatResult) UnmapLatency(b *BdevIostat) time.Duration {
+	return r.averageLatency(b.UnmapLatencyTicks, b.NumUnmapOps)
+}
+
+func (r *IostatResult) averageLatency(ticks, ops int) time.Duration {
+	if ops == 0 {
+		return 0
+	}
+	return r.TicksToDuration(uint64(ticks / ops))
+}
+
+// Total sums the counters of all bdevs in the result, the queue depth
+// fields are not summed since they are per device samples
+func (r *IostatResult) Total() BdevIostat {
+	var total BdevIostat
+	for i := range r.Bdevs {
+		b := &r.Bdevs[i]
+		total.BytesRead += b.BytesRead
+		total.NumReadOps += b.NumReadOps
+		total.BytesWritten += b.BytesWritten
+		total.NumWriteOps += b.NumWriteOps
+		total.BytesUnmapped += b.BytesUnmapped
+		total.NumUnmapOps += b.NumUnmapOps
+		total.ReadLatencyTicks += b.ReadLatencyTicks
+		total.WriteLatencyTicks += b.WriteLatencyTicks
+		total.UnmapLatencyTicks += b.UnmapLatencyTicks
+	}
+	return total
+}
+
+// IostatRate holds the IO rates of bdevs between two bdev_get_iostat samples,
+// the bandwidth is in MiB per second like iostat.py reports it
+type IostatRate struct {
+	Elapsed    time.Duration
+	ReadIOPS   float64
+	WriteIOPS  float64
+	ReadMiBps  float64
+	WriteMiBps float64
+}
+
+// RateSince computes the IO rates of all bdevs in the result since the
+// earlier sample, timed by the SPDK ticks between them rather than by when
+// the samples were received. Counters that went backwards, e.g. because a
+// bdev was recreated in between, fail with FailedPrecondition.
+func (r *IostatResult) RateSince(earlier *IostatResult) (IostatRate, error) {
+	if r.Ticks <= earlier.Ticks || r.TickRate == 0 {
+		return IostatRate{}, status.Error(codes.FailedPrecondition, "no ticks elapsed between iostat samples")
+	}
+	before, after := earlier.Total(), r.Total()
+	if after.NumReadOps < before.NumReadOps || after.NumWriteOps < before.NumWriteOps ||
+		after.BytesRead < before.BytesRead || after.BytesWritten < before.BytesWritten {
+		return IostatRate{}, status.Error(codes.FailedPrecondition, "iostat counters went backwards between samples")
+	}
+	elapsed := r.TicksToDuration(uint64(r.Ticks - earlier.Ticks))
+	seconds := elapsed.Seconds()
+	const mib = 1024 * 1024
+	return IostatRate{
+		Elapsed:    elapsed,
+		ReadIOPS:   float64(after.NumReadOps-before.NumReadOps) / seconds,
+		WriteIOPS:  float64(after.NumWriteOps-before.NumWriteOps) / seconds,
+		ReadMiBps:  float64(after.BytesRead-before.BytesRead) / mib / seconds,
+		WriteMiBps: float64(after.BytesWritten-before.BytesWritten) / mib / seconds,
+	}, nil
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIostatResult_TicksToDuration(t *testing.T) {
+	tests := map[string]struct {
+		tickRate int
+		ticks    uint64
+		want     time.Duration
+	}{
+		"unknown tick rate": {0, 100, 0},
+		"whole seconds":     {2000000000, 4000000000, 2 * time.Second},
+		"sub second":        {2000000000, 3000, 1500 * time.Nanosecond},
+		"large tick count":  {1000000000, math.MaxUint64 / 4, time.Duration(math.MaxUint64/4) * time.Nanosecond},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := IostatResult{TickRate: tt.tickRate}
+			if got := r.TicksToDuration(tt.ticks); got != tt.want {
+				t.Error("duration: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestIostatResult_Latency(t *testing.T) {
+	r := IostatResult{TickRate: 1000000, Ticks: 5000000}
+	b := BdevIostat{NumReadOps: 4, ReadLatencyTicks: 400, NumWriteOps: 0, WriteLatencyTicks: 10, NumUnmapOps: 1, UnmapLatencyTicks: 1000}
+	if got := r.Uptime(); got != 5*time.Second {
+		t.Error("uptime: expected", 5*time.Second, "received", got)
+	}
+	if got := r.ReadLatency(&b); got != 100*time.Microsecond {
+		t.Error("read: expected", 100*time.Microsecond, "received", got)
+	}
+	if got := r.WriteLatency(&b); got != 0 {
+		t.Error("write: expected", 0, "received", got)
+	}
+	if got := r.UnmapLatency(&b); got != time.Millisecond {
+		t.Error("unmap: expected", time.Millisecond, "received", got)
+	}
+}
+
+func TestIostatResult_Total(t *testing.T) {
+	r := IostatResult{Bdevs: []BdevIostat{
+		{Name: "Malloc0", BytesRead: 10, NumReadOps: 1, BytesWritten: 20, NumWriteOps: 2, QueueDepth: 7},
+		{Name: "Malloc1", BytesRead: 5, NumReadOps: 1, BytesUnmapped: 8, NumUnmapOps: 1, ReadLatencyTicks: 3},
+	}}
+	want := BdevIostat{BytesRead: 15, NumReadOps: 2, BytesWritten: 20, NumWriteOps: 2, BytesUnmapped: 8, NumUnmapOps: 1, ReadLatencyTicks: 3}
+	if got := r.Total(); !reflect.DeepEqual(got, want) {
+		t.Error("total: expected", want, "received", got)
+	}
+}
+
+func TestIostatResult_RateSince(t *testing.T) {
+	earlier := IostatResult{TickRate: 1000, Ticks: 1000, Bdevs: []BdevIostat{
+		{Name: "Malloc0", NumReadOps: 100, BytesRead: 1 << 20, NumWriteOps: 10, BytesWritten: 0},
+		{Name: "Malloc1", NumReadOps: 100},
+	}}
+	tests := map[string]struct {
+		later    IostatResult
+		want     IostatRate
+		wantCode codes.Code
+	}{
+		"two seconds": {
+			IostatResult{TickRate: 1000, Ticks: 3000, Bdevs: []BdevIostat{
+				{Name: "Malloc0", NumReadOps: 300, BytesRead: 5 << 20, NumWriteOps: 30, BytesWritten: 2 << 20},
+				{Name: "Malloc1", NumReadOps: 200},
+			}},
+			IostatRate{Elapsed: 2 * time.Second, ReadIOPS: 150, WriteIOPS: 10, ReadMiBps: 2, WriteMiBps: 1},
+			codes.OK,
+		},
+		"no ticks elapsed": {
+			IostatResult{TickRate: 1000, Ticks: 1000, Bdevs: earlier.Bdevs},
+			IostatRate{},
+			codes.FailedPrecondition,
+		},
+		"counters reset": {
+			IostatResult{TickRate: 1000, Ticks: 2000, Bdevs: []BdevIostat{{Name: "Malloc0"}}},
+			IostatRate{},
+			codes.FailedPrecondition,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.later.RateSince(&earlier)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
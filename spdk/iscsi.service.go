@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// IscsiService is interface to all iSCSI target functions in spdk
+type IscsiService interface {
+	CreateIscsiTargetNode(ctx context.Context, params IscsiTargetParams) error
+	GetIscsiTargetNodes(ctx context.Context) ([]IscsiTargetNode, error)
+	DeleteIscsiTargetNode(ctx context.Context, name string) error
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// IscsiServiceImpl implements IscsiService interface
+type IscsiServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ IscsiService = (*IscsiServiceImpl)(nil)
+
+// NewIscsiService is a constructor for IscsiServiceImpl
+func NewIscsiService(client JSONRPC) *IscsiServiceImpl {
+	return &IscsiServiceImpl{client}
+}
+
+// CreateIscsiTargetNode creates an iSCSI target node. SPDK reports a name that
+// is already in use as invalid parameters, so that error is checked against
+// the existing target nodes and reported as ErrIscsiTargetNodeExists.
+func (p *IscsiServiceImpl) CreateIscsiTargetNode(ctx context.Context, params IscsiTargetParams) error {
+	var result IscsiCreateTargetNodeResult
+	err := p.client.Call(ctx, "iscsi_create_target_node", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && (rpcErr.Code == InvalidParamsCode || rpcErr.Code == EEXISTCode) && p.hasTargetNode(ctx, params.Name) {
+			return &sentinelError{sentinel: ErrIscsiTargetNodeExists, err: err}
+		}
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create iSCSI target node: %s", params.Name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetIscsiTargetNodes lists all iSCSI target nodes
+func (p *IscsiServiceImpl) GetIscsiTargetNodes(ctx context.Context) ([]IscsiTargetNode, error) {
+	var result []IscsiTargetNode
+	err := p.client.Call(ctx, "iscsi_get_target_nodes", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteIscsiTargetNode deletes an iSCSI target node
+func (p *IscsiServiceImpl) DeleteIscsiTargetNode(ctx context.Context, name string) error {
+	params := IscsiDeleteTargetNodeParams{
+		Name: name,
+	}
+	var result IscsiDeleteTargetNodeResult
+	err := p.client.Call(ctx, "iscsi_delete_target_node", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete iSCSI target node: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// hasTargetNode reports whether a target node with the given name exists,
+// a failure to list them counts as not existing
+func (p *IscsiServiceImpl) hasTargetNode(ctx context.Context, name string) bool {
+	nodes, err := p.GetIscsiTargetNodes(ctx)
+	if err != nil {
+		return false
+	}
+	for _, node := range nodes {
+		if node.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIscsiService_CreateIscsiTargetNode(t *testing.T) {
+	invalid := &RPCError{Code: InvalidParamsCode, Message: "Invalid parameters"}
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+		exists  bool
+	}{
+		"created": {
+			NewMockJSONRPC().On("iscsi_create_target_node", true),
+			nil,
+			false,
+		},
+		"already exists": {
+			NewMockJSONRPC().OnError("iscsi_create_target_node", invalid).
+				On("iscsi_get_target_nodes", `[{"name":"iqn.2016-06.io.spdk:disk1"}]`),
+			invalid,
+			true,
+		},
+		"invalid parameters": {
+			NewMockJSONRPC().OnError("iscsi_create_target_node", invalid).
+				On("iscsi_get_target_nodes", `[]`),
+			invalid,
+			false,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("iscsi_create_target_node", false),
+			ErrUnexpectedSpdkCallResult,
+			false,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := IscsiTargetParams{
+				Name:       "iqn.2016-06.io.spdk:disk1",
+				AliasName:  "Data Disk1",
+				PgIgMaps:   []IscsiPgIgMap{{PgTag: 1, IgTag: 1}},
+				Luns:       []IscsiLun{{BdevName: "Malloc0", LunID: 0}},
+				QueueDepth: 64,
+			}
+			err := NewIscsiService(tt.mock).CreateIscsiTargetNode(context.Background(), params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if errors.Is(err, ErrIscsiTargetNodeExists) != tt.exists {
+				t.Error("exists: expected", tt.exists, "received", err)
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, &params) {
+				t.Error("args: expected", &params, "received", args)
+			}
+		})
+	}
+}
+
+func TestIscsiService_GetIscsiTargetNodes(t *testing.T) {
+	mock := NewMockJSONRPC().On("iscsi_get_target_nodes", `[{"name":"iqn.2016-06.io.spdk:disk1","alias_name":"Data Disk1",
+		"pg_ig_maps":[{"pg_tag":1,"ig_tag":1}],"luns":[{"bdev_name":"Malloc0","lun_id":0}],"queue_depth":64,
+		"disable_chap":true,"require_chap":false,"mutual_chap":false,"chap_group":0,"header_digest":false,"data_digest":false}]`)
+	got, err := NewIscsiService(mock).GetIscsiTargetNodes(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []IscsiTargetNode{{
+		Name:        "iqn.2016-06.io.spdk:disk1",
+		AliasName:   "Data Disk1",
+		PgIgMaps:    []IscsiPgIgMap{{PgTag: 1, IgTag: 1}},
+		Luns:        []IscsiLun{{BdevName: "Malloc0", LunID: 0}},
+		QueueDepth:  64,
+		DisableChap: true,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestIscsiService_DeleteIscsiTargetNode(t *testing.T) {
+	mock := NewMockJSONRPC().On("iscsi_delete_target_node", true)
+	if err := NewIscsiService(mock).DeleteIscsiTargetNode(context.Background(), "iqn.2016-06.io.spdk:disk1"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := &IscsiDeleteTargetNodeParams{Name: "iqn.2016-06.io.spdk:disk1"}
+	if args := mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+		t.Error("args: expected", want, "received", args)
+	}
+}
@@ -6,21 +6,28 @@
 package spdk
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -29,45 +36,255 @@ var (
 	ErrFailedSpdkCall = status.Error(codes.Unknown, "Failed to execute SPDK call")
 	// ErrUnexpectedSpdkCallResult indicates that the bridge got an error from SPDK
 	ErrUnexpectedSpdkCallResult = status.Error(codes.FailedPrecondition, "Unexpected SPDK call result.")
+	// ErrResponseIDMismatch indicates that SPDK answered with an id other than
+	// the one sent, which means the connection is out of sync
+	ErrResponseIDMismatch = status.Error(codes.Internal, "json response ID mismatch")
+	// ErrResponseVersionMismatch indicates that the peer answered with a
+	// jsonrpc version other than the one expected, e.g. because the client
+	// points at a service that is not SPDK
+	ErrResponseVersionMismatch = status.Error(codes.Internal, "json response version mismatch")
+	// ErrEmptyResponse indicates that SPDK closed the connection after the
+	// request was sent without writing any response at all, as it may do on
+	// shutdown. A partial or malformed response is reported differently.
+	ErrEmptyResponse = status.Error(codes.Internal, "SPDK closed the connection without a response")
+
+	// errEmptyMethod is returned before dialing when no method is given
+	errEmptyMethod = status.Error(codes.InvalidArgument, "empty method is not allowed")
 )
 
 // JSONRPC represents an interface to execute JSON RPC to SPDK
 type JSONRPC interface {
 	GetID() uint64
-	GetVersion(context.Context) string
+	GetVersion(context.Context) (SpdkVersion, error)
 	StartUnixListener() net.Listener
 	Call(ctx context.Context, method string, args, result interface{}) error
 }
 
 // Client implements JSONRPC interface
 type Client struct {
-	transport string
-	socket    string
-	id        uint64
-	tracer    trace.Tracer
+	transport   string
+	socket      string
+	id          uint64
+	rpcVersion  string
+	generateID  func() uint64
+	tracer      trace.Tracer
+	timeout     time.Duration
+	dialTimeout time.Duration
+	logger      Logger
+	redacted    map[string]struct{}
+	logRedactor func([]byte) []byte
+	onRequest   RequestHook
+	onResponse  ResponseHook
+	wireTap     WireTap
+
+	noRequestLog  bool
+	noResponseLog bool
+
+	retryAttempts int
+	retryDelay    time.Duration
+	dialer        DialFunc
+	readBuffer    int
+	writeBuffer   int
+	tlsConfig     *tls.Config
+	peerCred      *peerCred
+
+	maxResponseBytes int64
+	strictDecoding   bool
+	metrics          MetricsHook
+	inflight         chan struct{}
+	interceptors     []CallInterceptor
+
+	noHalfClose    bool
+	streamRequests bool
+	streamMethods  map[string]struct{}
+	persistent     bool
+	multiplex      bool
+	idleTimeout    time.Duration
+	keepAlive      time.Duration
+	stopPings      context.CancelFunc
+	pingsDone      chan struct{}
+	mu             sync.Mutex
+	lastUsed       time.Time
+	conn           net.Conn
+	limiter        io.Reader
+	decoder        *json.Decoder
+	mux            *muxConn
+}
+
+// Endpoint is implemented by JSONRPC clients that can report where they connect,
+// type-assert a JSONRPC to it for diagnostics
+type Endpoint interface {
+	Transport() string
+	Socket() string
 }
 
 // build time check that struct implements interface
 var _ JSONRPC = (*Client)(nil)
 
+// build time check that struct implements interface
+var _ Endpoint = (*Client)(nil)
+
 // NewClient creates a new instance of JSONRPC which is capable to
 // interact with either unix domain socket, e.g.: /var/tmp/spdk.sock
 // or with tcp connection ip and port tuple, e.g.: 10.1.1.2:1234
-func NewClient(socketPath string) *Client {
+// The transport can be forced with a unix://, tcp:// or tcp6:// prefix,
+// e.g.: tcp://[fe80::1]:4420, and on Linux a leading @ selects an abstract
+// unix socket, e.g.: @spdk.sock
+// NewClient panics on an empty socketPath, use NewClientE to get an error instead.
+func NewClient(socketPath string, opts ...Option) *Client {
 	if socketPath == "" {
 		log.Panic("empty socketPath is not allowed")
 	}
-	protocol := "tcp"
-	if _, _, err := net.SplitHostPort(socketPath); err != nil {
-		protocol = "unix"
+	return newClient(socketPath, opts)
+}
+
+// NewClientE is like NewClient but returns an error, instead of panicking,
+// when socketPath is empty or malformed
+func NewClientE(socketPath string, opts ...Option) (*Client, error) {
+	if err := validateAddress(socketPath); err != nil {
+		return nil, err
+	}
+	return newClient(socketPath, opts), nil
+}
+
+// newClient applies the defaults and the options on top of the detected transport
+func newClient(socketPath string, opts []Option) *Client {
+	protocol, address := detectTransport(socketPath)
+	client := &Client{
+		transport:  protocol,
+		socket:     address,
+		id:         0,
+		rpcVersion: JSONRPCVersion,
+		tracer:     otel.Tracer(""),
+		timeout:    DefaultTimeout,
+		logger:     log.Default(),
+
+		maxResponseBytes: DefaultMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.logger.Printf("Connection to SPDK will be via: %s detected from %s", protocol, socketPath)
+	return client
+}
+
+// Clone returns a new client for another SPDK endpoint with the same options
+// as r, detecting the transport of socketPath as NewClient does. The clone has
+// its own connection, request id counter and concurrency limit, while the
+// logger, hooks, wire tap, id generator, dialer and TLS configuration are
+// shared. Clone panics on an empty socketPath like NewClient.
+func (r *Client) Clone(socketPath string) *Client {
+	if socketPath == "" {
+		log.Panic("empty socketPath is not allowed")
+	}
+	protocol, address := detectTransport(socketPath)
+	client := &Client{
+		transport:   protocol,
+		socket:      address,
+		rpcVersion:  r.rpcVersion,
+		generateID:  r.generateID,
+		tracer:      r.tracer,
+		timeout:     r.timeout,
+		dialTimeout: r.dialTimeout,
+		logger:      r.logger,
+		redacted:    r.redacted,
+		logRedactor: r.logRedactor,
+		onRequest:   r.onRequest,
+		onResponse:  r.onResponse,
+		wireTap:     r.wireTap,
+
+		noRequestLog:  r.noRequestLog,
+		noResponseLog: r.noResponseLog,
+
+		retryAttempts: r.retryAttempts,
+		retryDelay:    r.retryDelay,
+		dialer:        r.dialer,
+		readBuffer:    r.readBuffer,
+		writeBuffer:   r.writeBuffer,
+		tlsConfig:     r.tlsConfig,
+		peerCred:      r.peerCred,
+
+		maxResponseBytes: r.maxResponseBytes,
+		strictDecoding:   r.strictDecoding,
+		metrics:          r.metrics,
+		interceptors:     append([]CallInterceptor(nil), r.interceptors...),
+
+		noHalfClose:    r.noHalfClose,
+		streamRequests: r.streamRequests,
+		streamMethods:  r.streamMethods,
+		persistent:     r.persistent,
+		multiplex:      r.multiplex,
+		idleTimeout:    r.idleTimeout,
+		keepAlive:      r.keepAlive,
+	}
+	if r.inflight != nil {
+		client.inflight = make(chan struct{}, cap(r.inflight))
+	}
+	client.logger.Printf("Connection to SPDK will be via: %s detected from %s", protocol, socketPath)
+	return client
+}
+
+// addressSchemes lists the explicit transport prefixes accepted by NewClient
+var addressSchemes = []string{"unix", "tcp", "tcp6"}
+
+// detectTransport strips an explicit scheme from the address or, when there is
+// none, treats it as tcp only if it is a host:port pair with a numeric port.
+// An abstract unix socket is always unix and is noted with a leading @, which
+// is also how a leading NUL byte is rewritten.
+func detectTransport(address string) (string, string) {
+	if strings.HasPrefix(address, "\x00") {
+		address = "@" + address[1:]
+	}
+	if isAbstractSocket(address) {
+		return "unix", address
+	}
+	for _, scheme := range addressSchemes {
+		if strings.HasPrefix(address, scheme+"://") {
+			return scheme, strings.TrimPrefix(address, scheme+"://")
+		}
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || strings.Contains(host, "/") {
+		return "unix", address
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "unix", address
+	}
+	return "tcp", address
+}
+
+// isAbstractSocket reports whether address names a socket in the Linux abstract
+// namespace, which net turns into a leading NUL byte and which has no file to
+// clean up. Other platforms treat it as a plain path.
+func isAbstractSocket(address string) bool {
+	return strings.HasPrefix(address, "@")
+}
+
+// validateAddress rejects addresses NewClient would not be able to connect to
+func validateAddress(socketPath string) error {
+	if socketPath == "" {
+		return status.Error(codes.InvalidArgument, "empty socketPath is not allowed")
+	}
+	if strings.ContainsRune(strings.TrimPrefix(socketPath, "\x00"), 0) {
+		return status.Errorf(codes.InvalidArgument, "socketPath %q contains a NUL byte", socketPath)
 	}
-	log.Printf("Connection to SPDK will be via: %s detected from %s", protocol, socketPath)
-	return &Client{
-		transport: protocol,
-		socket:    socketPath,
-		id:        0,
-		tracer:    otel.Tracer(""),
+	protocol, address := detectTransport(socketPath)
+	if i := strings.Index(socketPath, "://"); i >= 0 && address == socketPath {
+		return status.Errorf(codes.InvalidArgument, "unsupported scheme %q in socketPath, expected one of %v", socketPath[:i], addressSchemes)
 	}
+	if address == "" {
+		return status.Errorf(codes.InvalidArgument, "missing address in socketPath %q", socketPath)
+	}
+	if protocol != "unix" {
+		_, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid tcp address %q: %v", address, err)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid port %q in tcp address %q", port, address)
+		}
+	}
+	return nil
 }
 
 // GetID implements low level rpc request/response handling
@@ -75,23 +292,49 @@ func (r *Client) GetID() uint64 {
 	return r.id
 }
 
-// GetVersion implements low level rpc request/response handling
-func (r *Client) GetVersion(ctx context.Context) string {
-	var ver GetVersionResult
+// Transport returns the network used to reach SPDK, as detected by NewClient
+func (r *Client) Transport() string {
+	return r.transport
+}
+
+// Socket returns the address used to reach SPDK, without any scheme prefix
+func (r *Client) Socket() string {
+	return r.socket
+}
+
+// nextID returns the id of the next request, from the configured generator if any
+func (r *Client) nextID() uint64 {
+	if r.generateID != nil {
+		return r.generateID()
+	}
+	return atomic.AddUint64(&r.id, 1)
+}
+
+// GetVersion asks SPDK for its version, callers can gate optional methods
+// on the result with SpdkVersion.AtLeast
+func (r *Client) GetVersion(ctx context.Context) (SpdkVersion, error) {
+	var ver SpdkVersion
 	err := r.Call(ctx, "spdk_get_version", nil, &ver)
 	if err != nil {
-		msg := fmt.Sprintf("Could not get spdk version: %v", err)
-		log.Print(msg)
-		return ""
+		r.logger.Printf("Could not get spdk version: %v", err)
+		return SpdkVersion{}, err
 	}
-	log.Printf("Received from SPDK: %v", ver)
-	return ver.Version
+	return ver, nil
+}
+
+// Ping checks that SPDK is reachable and responsive with the lightweight
+// spdk_get_version call, the configured timeouts apply as to any other call
+func (r *Client) Ping(ctx context.Context) error {
+	var ver SpdkVersion
+	return r.Call(ctx, "spdk_get_version", nil, &ver)
 }
 
 // StartUnixListener is utility function used to create new listener in tests
 func (r *Client) StartUnixListener() net.Listener {
-	if err := os.RemoveAll(r.socket); err != nil {
-		log.Fatal(err)
+	if !isAbstractSocket(r.socket) {
+		if err := os.RemoveAll(r.socket); err != nil {
+			log.Fatal(err)
+		}
 	}
 	ln, err := net.Listen("unix", r.socket)
 	if err != nil {
@@ -102,77 +345,484 @@ func (r *Client) StartUnixListener() net.Listener {
 
 // Call implements low level rpc request/response handling
 func (r *Client) Call(ctx context.Context, method string, args, result interface{}) error {
-	id := atomic.AddUint64(&r.id, 1)
+	if len(r.interceptors) != 0 {
+		return r.intercept(ctx, 0, method, args, result)
+	}
+	return r.call(ctx, method, args, result)
+}
 
-	_, childSpan := r.tracer.Start(ctx, "spdk."+method)
-	defer childSpan.End()
+// call sends the request and decodes the result, without interceptors
+func (r *Client) call(ctx context.Context, method string, args, result interface{}) error {
+	return r.observe(method, func() error {
+		_, err := r.rawCall(ctx, method, args, func(raw json.RawMessage) error {
+			if err := r.decodeResult(raw, result); err != nil {
+				return &ResultDecodeError{Method: method, Raw: raw, Err: err}
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// observe reports the duration and error of f to the metrics hook, if any
+func (r *Client) observe(method string, f func() error) error {
+	if r.metrics == nil {
+		return f()
+	}
+	start := time.Now()
+	err := f()
+	r.metrics.ObserveCall(method, time.Since(start), err)
+	return err
+}
+
+// decodeResult unmarshals raw into result, rejecting fields result has no
+// place for when strict decoding is enabled
+func (r *Client) decodeResult(raw json.RawMessage, result interface{}) error {
+	if !r.strictDecoding || result == nil {
+		return json.Unmarshal(raw, &result)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(result)
+}
+
+// RawCall performs the same request/response handling as Call, including the
+// id and error checks, but returns the result undecoded
+func (r *Client) RawCall(ctx context.Context, method string, args interface{}) (raw json.RawMessage, err error) {
+	err = r.observe(method, func() error {
+		raw, err = r.rawCall(ctx, method, args, nil)
+		return err
+	})
+	return raw, err
+}
+
+// rawCall sends a single request to SPDK and checks the response, decode,
+// when given, runs on the result within the span of the call
+func (r *Client) rawCall(ctx context.Context, method string, args interface{}, decode func(json.RawMessage) error) (_ json.RawMessage, err error) {
+	if method == "" {
+		return nil, errEmptyMethod
+	}
+	if err := validateParams(method, args); err != nil {
+		return nil, err
+	}
+	id := r.nextID()
+
+	ctx, childSpan := r.tracer.Start(ctx, "spdk."+method, trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { endSpan(childSpan, err) }()
 
 	if childSpan.IsRecording() {
 		childSpan.SetAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
 			attribute.Int64("request.id", int64(id)),
 			attribute.String("spdk.socket", r.socket),
 			attribute.String("spdk.transport", r.transport),
 		)
 	}
 
+	raw, err := r.roundTrip(ctx, childSpan, id, method, args)
+	if err != nil || decode == nil {
+		return raw, err
+	}
+	return raw, decode(raw)
+}
+
+// roundTrip sends the request, streamed or marshalled, and checks the response
+func (r *Client) roundTrip(ctx context.Context, childSpan trace.Span, id uint64, method string, args interface{}) (json.RawMessage, error) {
+	if r.streams(method) {
+		return r.streamedCall(ctx, id, method, args)
+	}
+
+	data, params, err := r.marshalRequest(id, method, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", method, err)
+	}
+
+	var logged []byte
+	if r.onRequest != nil {
+		r.onRequest(method, id, r.redact(params))
+	} else if !r.noRequestLog {
+		logged = r.redact(data)
+		r.logger.Printf("Sending to SPDK method=%s id=%d: %s", method, id, logged)
+	}
+	if childSpan.IsRecording() {
+		if logged == nil {
+			logged = r.redact(data)
+		}
+		childSpan.SetAttributes(attribute.String("rpc.request", string(logged)))
+	}
+
+	start := time.Now()
+	response, err := r.exchangeWithRetry(ctx, method, func(ctx context.Context) (RPCResponse, error) {
+		return r.exchange(ctx, id, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return r.checkResponse(method, id, response, start)
+}
+
+// checkResponse reports the response to the hook or the log and turns an
+// unexpected id or a JSON-RPC error into the error of the call
+func (r *Client) checkResponse(method string, id uint64, response RPCResponse, start time.Time) (json.RawMessage, error) {
+	if r.onResponse != nil {
+		var rpcErr *RPCError
+		if response.Error.Code != 0 {
+			copied := response.Error
+			copied.Method = method
+			rpcErr = &copied
+		}
+		r.onResponse(method, id, r.redact(response.Result), rpcErr, time.Since(start))
+	} else if !r.noResponseLog {
+		jsonresponse, _ := json.Marshal(response)
+		// the id logged is the one sent, a mismatching response still shows its own
+		r.logger.Printf("Received from SPDK method=%s id=%d: %s", method, id, r.redact(jsonresponse))
+	}
+	return r.verifyResponse(method, id, response)
+}
+
+// verifyResponse turns an unexpected id, version or a JSON-RPC error into
+// the error of the call
+func (r *Client) verifyResponse(method string, id uint64, response RPCResponse) (json.RawMessage, error) {
+	if response.ID != id {
+		if r.persistent && !r.multiplex {
+			// whatever is buffered on the connection belongs to another request,
+			// the keep-alive pings may get here too so they are left running
+			r.mu.Lock()
+			_ = r.closeLocked()
+			r.mu.Unlock()
+		}
+		return nil, fmt.Errorf("%s: %w: expected %d received %d", method, ErrResponseIDMismatch, id, response.ID)
+	}
+	if err := r.checkVersion(response.JSONRPCVersion); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if response.Error.Code != 0 {
+		rpcErr := response.Error
+		rpcErr.Method = method
+		return nil, &rpcErr
+	}
+	return response.Result, nil
+}
+
+// checkVersion verifies that a response is a JSON-RPC 2.0 one, whatever
+// version requests are sent with, a missing jsonrpc member is only rejected
+// by strict decoding
+func (r *Client) checkVersion(version string) error {
+	if version == JSONRPCVersion || version == "" && !r.strictDecoding {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %q received %q", ErrResponseVersionMismatch, JSONRPCVersion, version)
+}
+
+// marshalRequest encodes the request, the params are only encoded separately,
+// and returned, when a request hook needs them
+func (r *Client) marshalRequest(id uint64, method string, args interface{}) (data []byte, params json.RawMessage, err error) {
+	args = omitNilParams(args)
 	request := RPCRequest{
-		RPCVersion: JSONRPCVersion,
+		RPCVersion: r.rpcVersion,
 		ID:         id,
 		Method:     method,
 		Params:     args,
 	}
-	data, err := json.Marshal(request)
-	if err != nil {
-		return fmt.Errorf("%s: %s", method, err)
+	if r.onRequest != nil && args != nil {
+		if params, err = json.Marshal(args); err != nil {
+			return nil, nil, err
+		}
+		request.Params = params
 	}
+	data, err = json.Marshal(request)
+	return data, params, err
+}
 
-	log.Printf("Sending to SPDK: %s", data)
-
-	resp, _ := r.communicate(data)
+// endSpan records err, if any, as the span status and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
 
-	var response RPCResponse
-	err = json.NewDecoder(resp).Decode(&response)
-	jsonresponse, _ := json.Marshal(response)
-	log.Printf("Received from SPDK: %s", jsonresponse)
+// exchange sends the request to SPDK and decodes a single response
+func (r *Client) exchange(ctx context.Context, id uint64, buf []byte) (RPCResponse, error) {
+	release, err := r.acquire(ctx)
 	if err != nil {
-		return fmt.Errorf("%s: %s", method, err)
+		return RPCResponse{}, err
 	}
-	if response.ID != id {
-		return fmt.Errorf("%s: json response ID mismatch", method)
+	defer release()
+	if r.multiplex {
+		return r.exchangeMultiplexed(ctx, id, buf)
 	}
-	if response.Error.Code != 0 {
-		return fmt.Errorf("%s: json response error: %s", method, response.Error.Message)
+	if r.persistent {
+		return r.exchangePersistent(ctx, buf)
 	}
-	err = json.Unmarshal(response.Result, &result)
+	resp, err := r.communicate(ctx, buf)
 	if err != nil {
-		return fmt.Errorf("%s: %s", method, err)
+		return RPCResponse{}, err
 	}
-	return nil
+	return decodeResponse(resp)
 }
 
-func (r *Client) communicate(buf []byte) (io.Reader, error) {
-	// connect
-	conn, err := net.Dial(r.transport, r.socket)
+// decodeResponse decodes the single response read from a dedicated connection
+func decodeResponse(resp []byte) (RPCResponse, error) {
+	var response RPCResponse
+	if len(bytes.TrimSpace(resp)) == 0 {
+		return response, ErrEmptyResponse
+	}
+	if err := json.Unmarshal(resp, &response); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// dial connects to the configured SPDK socket
+func (r *Client) dial(ctx context.Context) (net.Conn, error) {
+	dial := r.dialer
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	dialCtx := ctx
+	if r.dialTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, r.dialTimeout)
+		defer cancel()
+	}
+	conn, err := dial(dialCtx, r.transport, r.socket)
 	if err != nil {
-		log.Fatal(err)
+		r.logger.Printf("%v", err)
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		// the dialer gives up at the call deadline, possibly before ctx reports it
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			return nil, status.FromContextError(context.DeadlineExceeded).Err()
+		}
+		// running out of dial timeout means SPDK is unreachable, not that the call took too long
+		return nil, status.Errorf(codes.Unavailable, "failed to connect to SPDK at %s: %v", r.socket, err)
+	}
+	r.setSocketBuffers(conn)
+	if r.peerCred != nil && r.transport == "unix" {
+		if err := checkPeerCred(conn, r.peerCred); err != nil {
+			_ = conn.Close()
+			r.logger.Printf("%v", err)
+			return nil, err
+		}
+	}
+	if r.tlsConfig != nil && r.transport != "unix" {
+		return r.handshake(ctx, conn)
+	}
+	return conn, nil
+}
+
+// setSocketBuffers applies the configured kernel buffer sizes to a TCP
+// connection, failing to do so only costs throughput and is just logged
+func (r *Client) setSocketBuffers(conn net.Conn) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return
+	}
+	if r.readBuffer > 0 {
+		if err := tcpConn.SetReadBuffer(r.readBuffer); err != nil {
+			r.logger.Printf("Could not set the receive buffer to %d bytes: %v", r.readBuffer, err)
+		}
+	}
+	if r.writeBuffer > 0 {
+		if err := tcpConn.SetWriteBuffer(r.writeBuffer); err != nil {
+			r.logger.Printf("Could not set the send buffer to %d bytes: %v", r.writeBuffer, err)
+		}
+	}
+}
+
+// handshake wraps the connection in TLS, bounded by the call deadline
+func (r *Client) handshake(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	config := r.tlsConfig.Clone()
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(r.socket); err == nil {
+			config.ServerName = host
+		}
+	}
+	if deadline, ok := r.deadline(ctx); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		r.logger.Printf("%v", err)
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Errorf(codes.Unavailable, "TLS handshake with SPDK at %s failed: %v", r.socket, err)
+	}
+	return tlsConn, nil
+}
+
+func (r *Client) communicate(ctx context.Context, buf []byte) ([]byte, error) {
+	return r.transmit(ctx, func(w io.Writer) error {
+		_, err := w.Write(buf)
+		return err
+	})
+}
+
+// transmit sends a request written by write over a dedicated connection and
+// reads the response
+func (r *Client) transmit(ctx context.Context, write func(io.Writer) error) ([]byte, error) {
+	if r.wireTap != nil {
+		write = tapWrites(write, r.wireTap)
+	}
+	conn, stop, err := r.dialAndWrite(ctx, write)
+	if isBrokenPipe(err) && ctx.Err() == nil {
+		// SPDK dropped the connection before reading anything of the request,
+		// so it was not processed and is safe to send once more
+		r.logger.Printf("Resending to SPDK on a new connection after: %v", err)
+		conn, stop, err = r.dialAndWrite(ctx, write)
 	}
-	// write
-	_, err = conn.Write(buf)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
+	defer conn.Close()
+	defer stop()
+	resp, err := r.readResponse(ctx, conn)
+	if err == nil && r.wireTap != nil {
+		r.wireTap("recv", resp)
+	}
+	return resp, err
+}
+
+// readResponse half-closes conn, unless configured otherwise, and reads the response
+func (r *Client) readResponse(ctx context.Context, conn net.Conn) ([]byte, error) {
+	if r.noHalfClose {
+		// the write side stays open, so the end of the response is where its
+		// JSON value ends rather than where the stream does
+		return r.readValue(ctx, conn)
+	}
+	var err error
 	// close
 	switch conn := conn.(type) {
+	case *tls.Conn:
+		err = conn.CloseWrite()
 	case *net.TCPConn:
 		err = conn.CloseWrite()
 	case *net.UnixConn:
 		err = conn.CloseWrite()
 	}
 	if err != nil {
-		log.Fatal(err)
+		r.logger.Printf("%v", err)
 		return nil, err
 	}
-	// read
-	return bufio.NewReader(conn), nil
+	// read, SPDK closes its side once the response is sent, so drain the
+	// whole stream before decoding however many packets it spans
+	return r.readAll(ctx, conn)
+}
+
+// dialAndWrite dials a dedicated connection and writes the request to it, the
+// returned stop function ends watching ctx and has to be called before the
+// connection is closed
+func (r *Client) dialAndWrite(ctx context.Context, write func(io.Writer) error) (net.Conn, func(), error) {
+	// connect
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// bound the whole exchange by the configured timeout or the context deadline, whichever is earlier
+	if deadline, ok := r.deadline(ctx); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+	}
+	stop := watchCancel(ctx, conn)
+	// write
+	err = write(conn)
+	if err != nil {
+		stop()
+		_ = conn.Close()
+		r.logger.Printf("%v", err)
+		return nil, nil, transportError(ctx, err)
+	}
+	return conn, stop, nil
+}
+
+// readAll reads conn until EOF, bounded by the configured maximum response size
+func (r *Client) readAll(ctx context.Context, conn net.Conn) ([]byte, error) {
+	var reader io.Reader = conn
+	if r.maxResponseBytes > 0 {
+		reader = io.LimitReader(conn, r.maxResponseBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		r.logger.Printf("%v", err)
+		return nil, transportError(ctx, err)
+	}
+	if r.maxResponseBytes > 0 && int64(len(data)) > r.maxResponseBytes {
+		return nil, ErrResponseTooLarge
+	}
+	return data, nil
+}
+
+// readValue reads a single JSON value from conn, bounded by the configured
+// maximum response size. A connection closed before anything was sent reads
+// as an empty response.
+func (r *Client) readValue(ctx context.Context, conn net.Conn) ([]byte, error) {
+	var data json.RawMessage
+	if err := json.NewDecoder(newLimitReader(conn, r.maxResponseBytes)).Decode(&data); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err == ErrResponseTooLarge {
+			return nil, err
+		}
+		r.logger.Printf("%v", err)
+		return nil, transportError(ctx, err)
+	}
+	return data, nil
+}
+
+// watchCancel unblocks any read or write pending on conn as soon as ctx is
+// cancelled, which a deadline alone does not cover. The returned function
+// stops the watcher and must be called once the I/O is done, after it
+// returns the watcher no longer touches conn.
+func watchCancel(ctx context.Context, conn net.Conn) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// deadline returns the earliest of the context deadline and the configured timeout
+func (r *Client) deadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if r.timeout > 0 {
+		if timeout := time.Now().Add(r.timeout); !ok || timeout.Before(deadline) {
+			return timeout, true
+		}
+	}
+	return deadline, ok
+}
+
+// transportError converts a socket error into a gRPC status error where possible
+func transportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return err
 }
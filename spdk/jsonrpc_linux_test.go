@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+//go:build linux
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSpdk_AbstractSocket(t *testing.T) {
+	name := fmt.Sprintf("gospdk-%d.sock", os.Getpid())
+	tests := map[string]struct {
+		address string
+	}{
+		"at prefix":   {"@" + name},
+		"nul prefix":  {"\x00" + name},
+		"unix scheme": {"unix://@" + name},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(tt.address, WithLogger(NopLogger{}))
+			if client.Transport() != "unix" {
+				t.Error("transport: expected unix received", client.Transport())
+			}
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			serve(ln, func(req RPCRequest) string {
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+			})
+
+			var result bool
+			if err := client.Call(context.Background(), "bdev_examine", nil, &result); err != nil || !result {
+				t.Error("call: expected true received", result, err)
+			}
+			if _, err := os.Lstat(client.Socket()); !os.IsNotExist(err) {
+				t.Error("file: expected none received", err)
+			}
+		})
+	}
+}
+
+func TestSpdk_WithSocketBuffers(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := NewClient(ln.Addr().String(), WithLogger(NopLogger{}), WithSocketBuffers(8<<10, 32<<10))
+	client.setSocketBuffers(conn)
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rcvbuf, sndbuf int
+	err = raw.Control(func(fd uintptr) {
+		rcvbuf, _ = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_RCVBUF)
+		sndbuf, _ = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_SNDBUF)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the kernel doubles the requested sizes for its own bookkeeping
+	if rcvbuf != 16<<10 {
+		t.Error("SO_RCVBUF: expected", 16<<10, "received", rcvbuf)
+	}
+	if sndbuf != 64<<10 {
+		t.Error("SO_SNDBUF: expected", 64<<10, "received", sndbuf)
+	}
+}
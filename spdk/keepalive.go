@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// startKeepAliveLocked starts pinging the persistent connection, unless
+// keep-alive is off or the pings are already running
+func (r *Client) startKeepAliveLocked() {
+	if r.keepAlive <= 0 || r.stopPings != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stopPings = cancel
+	r.pingsDone = make(chan struct{})
+	go r.keepAliveLoop(ctx, r.pingsDone)
+}
+
+// keepAliveLoop pings SPDK every time the connection went unused for the
+// keep-alive interval and redials it when a ping fails, until ctx is done,
+// closing done on return
+func (r *Client) keepAliveLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		idle := time.Since(r.lastUsed) >= r.keepAlive
+		r.mu.Unlock()
+		if !idle {
+			continue
+		}
+		// an error reply still proves the connection is alive
+		err := r.ping(ctx)
+		var rpcErr *RPCError
+		if err == nil || errors.As(err, &rpcErr) || ctx.Err() != nil {
+			continue
+		}
+		r.logger.Printf("Redialing SPDK after failed keep-alive: %v", err)
+		if err := r.Connect(ctx); err != nil {
+			r.logger.Printf("%v", err)
+		}
+	}
+}
+
+// ping sends spdk_get_version over the connection, bypassing interceptors,
+// retries, metrics, spans, hooks and logs so that keep-alive traffic does not
+// show up as calls
+func (r *Client) ping(ctx context.Context) error {
+	const method = "spdk_get_version"
+	id := r.nextID()
+	data, _, err := r.marshalRequest(id, method, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %s", method, err)
+	}
+	response, err := r.exchange(ctx, id, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	_, err = r.verifyResponse(method, id, response)
+	return err
+}
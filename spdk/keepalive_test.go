@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpdk_WithKeepAlive(t *testing.T) {
+	tests := map[string]struct {
+		opts      []Option
+		wantPings bool
+	}{
+		"persistent":  {[]Option{WithPersistentConnection()}, true},
+		"multiplexed": {[]Option{WithMultiplexing()}, true},
+		"per call":    {nil, false},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := append([]Option{WithLogger(NopLogger{}), WithKeepAlive(10 * time.Millisecond)}, tt.opts...)
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), opts...)
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			var pings int32
+			accepted := serve(ln, func(req RPCRequest) string {
+				atomic.AddInt32(&pings, 1)
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.09","fields":{"major":23,"minor":9}}}`, req.ID)
+			})
+
+			if err := client.Connect(context.Background()); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			client.mu.Lock()
+			done := client.pingsDone
+			client.mu.Unlock()
+			if running := done != nil; running != tt.wantPings {
+				t.Fatal("pings: expected", tt.wantPings, "received", running)
+			}
+			if !tt.wantPings {
+				return
+			}
+			deadline := time.Now().Add(time.Second)
+			for atomic.LoadInt32(&pings) < 2 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if err := client.Close(); err != nil {
+				t.Error("unexpected error", err)
+			}
+			// Close returns once the pings have stopped
+			select {
+			case <-done:
+			default:
+				t.Error("pings: expected stopped after Close")
+			}
+			if n := atomic.LoadInt32(&pings); n < 2 {
+				t.Error("pings: expected at least 2 received", n)
+			}
+			if n := atomic.LoadInt32(accepted); n != 1 {
+				t.Error("accepted: expected 1 received", n)
+			}
+		})
+	}
+}
+
+func TestSpdk_WithKeepAliveUnobserved(t *testing.T) {
+	logger := &recordingLogger{}
+	var observed int32
+	hook := MetricsHookFunc(func(string, time.Duration, error) { atomic.AddInt32(&observed, 1) })
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(logger), WithMetrics(hook),
+		WithPersistentConnection(), WithKeepAlive(10*time.Millisecond))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	var pings int32
+	serve(ln, func(req RPCRequest) string {
+		atomic.AddInt32(&pings, 1)
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.09"}}`, req.ID)
+	})
+	defer client.Close()
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&pings) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&pings); n < 2 {
+		t.Fatal("pings: expected at least 2 received", n)
+	}
+	if n := atomic.LoadInt32(&observed); n != 0 {
+		t.Error("metrics: expected no pings received", n)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	for _, line := range logger.lines {
+		if strings.Contains(line, "spdk_get_version") {
+			t.Error("log: expected no pings received", line)
+		}
+	}
+}
+
+func TestSpdk_WithKeepAliveRedial(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}),
+		WithPersistentConnection(), WithKeepAlive(10*time.Millisecond))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	var accepted int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// drop the connection Connect dialed, as a firewall would
+			if atomic.AddInt32(&accepted, 1) == 1 {
+				_ = conn.Close()
+				continue
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				decoder := json.NewDecoder(conn)
+				for {
+					var req RPCRequest
+					if err := decoder.Decode(&req); err != nil {
+						return
+					}
+					if _, err := io.WriteString(conn, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.09"}}`, req.ID)); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	defer client.Close()
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&accepted) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&accepted); n < 2 {
+		t.Fatal("accepted: expected a redial received", n)
+	}
+	var ver SpdkVersion
+	if err := client.Call(context.Background(), "spdk_get_version", nil, &ver); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxResponseBytes caps the size of a single SPDK response
+// when no other limit is configured
+const DefaultMaxResponseBytes = 64 << 20
+
+// ErrResponseTooLarge indicates that SPDK sent more than the configured maximum
+// response size before a complete JSON object could be decoded
+var ErrResponseTooLarge = status.Error(codes.ResourceExhausted, "SPDK response exceeds the maximum allowed size")
+
+// limitReader is like io.LimitReader but reports ErrResponseTooLarge instead of
+// a plain EOF once the limit is reached, and can be reset between responses
+type limitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+// newLimitReader wraps r unless limit is zero or negative, which disables the cap
+func newLimitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &limitReader{r: r, limit: limit, n: limit}
+}
+
+// Read implements io.Reader
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// resetLimit restores the full budget of r before the next response is decoded
+func resetLimit(r io.Reader) {
+	if l, ok := r.(*limitReader); ok {
+		l.n = l.limit
+	}
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// LogService is interface to all logging functions in spdk
+type LogService interface {
+	SetSpdkLogLevel(ctx context.Context, level string) error
+	GetSpdkLogLevel(ctx context.Context) (string, error)
+	SetSpdkLogFlag(ctx context.Context, flag string, enabled bool) error
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// logLevels lists the log levels SPDK accepts, from least to most verbose
+var logLevels = []string{"ERROR", "WARNING", "NOTICE", "INFO", "DEBUG"}
+
+// LogServiceImpl implements LogService interface
+type LogServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ LogService = (*LogServiceImpl)(nil)
+
+// NewLogService is a constructor for LogServiceImpl
+func NewLogService(client JSONRPC) *LogServiceImpl {
+	return &LogServiceImpl{client}
+}
+
+// SetSpdkLogLevel sets the level of the messages SPDK logs, the level is one
+// of ERROR, WARNING, NOTICE, INFO or DEBUG in any case
+func (p *LogServiceImpl) SetSpdkLogLevel(ctx context.Context, level string) error {
+	if !containsFold(logLevels, level) {
+		return status.Errorf(codes.InvalidArgument, "invalid log level %q, expected one of %v", level, logLevels)
+	}
+	params := LogSetLevelParams{
+		Level: strings.ToUpper(level),
+	}
+	var result LogSetLevelResult
+	err := p.client.Call(ctx, "log_set_level", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not set SPDK log level: %s", level)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetSpdkLogLevel returns the level of the messages SPDK logs
+func (p *LogServiceImpl) GetSpdkLogLevel(ctx context.Context) (string, error) {
+	var result string
+	err := p.client.Call(ctx, "log_get_level", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", err
+	}
+	return result, nil
+}
+
+// SetSpdkLogFlag enables or disables the debug messages of a single SPDK
+// component, e.g. nvmf or bdev_nvme, which SPDK only prints at DEBUG level
+func (p *LogServiceImpl) SetSpdkLogFlag(ctx context.Context, flag string, enabled bool) error {
+	if flag == "" {
+		return status.Error(codes.InvalidArgument, "missing log flag")
+	}
+	params := LogFlagParams{
+		Flag: flag,
+	}
+	method := "log_clear_flag"
+	if enabled {
+		method = "log_set_flag"
+	}
+	var result LogFlagResult
+	err := p.client.Call(ctx, method, &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not change SPDK log flag: %s", flag)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogService_SetSpdkLogLevel(t *testing.T) {
+	tests := map[string]struct {
+		level    string
+		mock     *MockJSONRPC
+		wantArgs []MockCall
+		wantErr  error
+		wantCode codes.Code
+	}{
+		"valid": {
+			"debug",
+			NewMockJSONRPC().On("log_set_level", true),
+			[]MockCall{{Method: "log_set_level", Args: &LogSetLevelParams{Level: "DEBUG"}}},
+			nil,
+			codes.OK,
+		},
+		"unexpected result": {
+			"ERROR",
+			NewMockJSONRPC().On("log_set_level", false),
+			[]MockCall{{Method: "log_set_level", Args: &LogSetLevelParams{Level: "ERROR"}}},
+			ErrUnexpectedSpdkCallResult,
+			codes.FailedPrecondition,
+		},
+		"invalid level": {
+			"TRACE",
+			NewMockJSONRPC(),
+			nil,
+			nil,
+			codes.InvalidArgument,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewLogService(tt.mock).SetSpdkLogLevel(context.Background(), tt.level)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", code)
+			}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, tt.wantArgs) {
+				t.Error("calls: expected", tt.wantArgs, "received", calls)
+			}
+		})
+	}
+}
+
+func TestLogService_GetSpdkLogLevel(t *testing.T) {
+	mock := NewMockJSONRPC().On("log_get_level", `"NOTICE"`)
+	got, err := NewLogService(mock).GetSpdkLogLevel(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if got != "NOTICE" {
+		t.Error("response: expected", "NOTICE", "received", got)
+	}
+}
+
+func TestLogService_SetSpdkLogFlag(t *testing.T) {
+	tests := map[string]struct {
+		enabled    bool
+		wantMethod string
+	}{
+		"enable":  {true, "log_set_flag"},
+		"disable": {false, "log_clear_flag"},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("log_set_flag", true).On("log_clear_flag", true)
+			if err := NewLogService(mock).SetSpdkLogFlag(context.Background(), "nvmf", tt.enabled); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			want := []MockCall{{Method: tt.wantMethod, Args: &LogFlagParams{Flag: "nvmf"}}}
+			if calls := mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Logger is used by Client to report the requests and responses it exchanges
+// with SPDK, *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// NopLogger is a Logger that discards everything
+type NopLogger struct{}
+
+// build time check that struct implements interface
+var _ Logger = NopLogger{}
+
+// Printf implements Logger by doing nothing
+func (NopLogger) Printf(string, ...interface{}) {}
+
+// RequestHook is called with the params of every request just before it is
+// sent to SPDK, see WithRequestHook
+type RequestHook func(method string, id uint64, params json.RawMessage)
+
+// ResponseHook is called with every response received from SPDK, rpcErr is
+// nil unless SPDK returned an error and dur is the time spent waiting for the
+// response, see WithResponseHook
+type ResponseHook func(method string, id uint64, result json.RawMessage, rpcErr *RPCError, dur time.Duration)
+
+// WireTap is called with the literal bytes written to and read from SPDK,
+// direction is "send" or "recv", see WithWireTap
+type WireTap func(direction string, b []byte)
@@ -10,15 +10,16 @@ import (
 
 // LvolService is interface to all logical volumes functions in spdk
 type LvolService interface {
-	CreateLvstore(context.Context, *NvmfCreateSubsystemParams) (*NvmfCreateSubsystemResult, error)
+	CreateLvstore(ctx context.Context, baseBdev string, lvsName string, clusterSize uint64) (string, error)
 	DeleteLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
 	GetLvstores(ctx context.Context, page int, limit int) (*NvmfGetSubsystemsResult, error)
 	RenameLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
 	GrowLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
 
-	CreateLvol(context.Context, *NvmfCreateSubsystemParams) (*NvmfCreateSubsystemResult, error)
-	SnapshotLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
-	CloneLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
+	CreateLvol(ctx context.Context, params LvolParams) (string, error)
+	SnapshotLvol(ctx context.Context, lvolName string, snapshotName string) (string, error)
+	CloneLvol(ctx context.Context, snapshotName string, cloneName string) (string, error)
+	InflateLvol(ctx context.Context, name string) error
 	RenameLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
 	ResizeLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
 	DeleteLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error)
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// LvolServiceImpl implements LvolService interface
+type LvolServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ LvolService = (*LvolServiceImpl)(nil)
+
+// NewLvolService is a constructor for LvolServiceImpl
+func NewLvolService(client JSONRPC) *LvolServiceImpl {
+	return &LvolServiceImpl{client}
+}
+
+// CreateLvstore creates an lvol store on top of baseBdev and returns its UUID,
+// a store that already exists is reported as ErrLvstoreExists and a base bdev
+// that is already claimed as ErrBdevClaimed
+func (p *LvolServiceImpl) CreateLvstore(ctx context.Context, baseBdev string, lvsName string, clusterSize uint64) (string, error) {
+	params := LvolStoreCreateParams{
+		BdevName:  baseBdev,
+		LvsName:   lvsName,
+		ClusterSz: clusterSize,
+	}
+	var result LvolStoreCreateResult
+	err := p.client.Call(ctx, "bdev_lvol_create_lvstore", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		err = wrapRPCError(err, ErrLvstoreExists, EEXISTCode)
+		return "", wrapRPCError(err, ErrBdevClaimed, EBUSYCode)
+	}
+	return string(result), nil
+}
+
+// DeleteLvstore deletes lvol store
+func (p *LvolServiceImpl) DeleteLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// GetLvstores gets lvol stores
+func (p *LvolServiceImpl) GetLvstores(_ context.Context, _ int, _ int) (*NvmfGetSubsystemsResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// RenameLvstore renames lvol store
+func (p *LvolServiceImpl) RenameLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// GrowLvstore grows lvol store
+func (p *LvolServiceImpl) GrowLvstore(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// CreateLvol creates a logical volume and returns the name of its bdev
+func (p *LvolServiceImpl) CreateLvol(ctx context.Context, params LvolParams) (string, error) {
+	var result LvolCreateResult
+	err := p.client.Call(ctx, "bdev_lvol_create", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", err
+	}
+	return string(result), nil
+}
+
+// SnapshotLvol takes a read-only snapshot of the lvol and returns its UUID,
+// a name that is already taken in the lvol store is reported as ErrLvolExists
+func (p *LvolServiceImpl) SnapshotLvol(ctx context.Context, lvolName string, snapshotName string) (string, error) {
+	params := LvolSnapshotParams{
+		LvolName:     lvolName,
+		SnapshotName: snapshotName,
+	}
+	var result LvolSnapshotResult
+	err := p.client.Call(ctx, "bdev_lvol_snapshot", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrLvolExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// CloneLvol creates a thin clone of the snapshot and returns its UUID,
+// a name that is already taken in the lvol store is reported as ErrLvolExists
+func (p *LvolServiceImpl) CloneLvol(ctx context.Context, snapshotName string, cloneName string) (string, error) {
+	params := LvolCloneParams{
+		SnapshotName: snapshotName,
+		CloneName:    cloneName,
+	}
+	var result LvolCloneResult
+	err := p.client.Call(ctx, "bdev_lvol_clone", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return "", wrapRPCError(err, ErrLvolExists, EEXISTCode)
+	}
+	return string(result), nil
+}
+
+// InflateLvol allocates all clusters of a thin clone and copies the data it
+// shares with its snapshot, which decouples it from the snapshot
+func (p *LvolServiceImpl) InflateLvol(ctx context.Context, name string) error {
+	params := LvolInflateParams{
+		Name: name,
+	}
+	var result LvolInflateResult
+	err := p.client.Call(ctx, "bdev_lvol_inflate", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not inflate lvol: %s", name)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// RenameLvol renames logical volume
+func (p *LvolServiceImpl) RenameLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// ResizeLvol resizes logical volume
+func (p *LvolServiceImpl) ResizeLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
+
+// DeleteLvol deletes logical volume
+func (p *LvolServiceImpl) DeleteLvol(context.Context, *NvmfDeleteSubsystemParams) (*NvmfDeleteSubsystemResult, error) {
+	// TBD
+	return nil, nil
+}
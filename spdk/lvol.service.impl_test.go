@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLvolService_CreateLvstore(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    string
+		wantErr error
+	}{
+		"created": {
+			NewMockJSONRPC().On("bdev_lvol_create_lvstore", `"a9959197-b5e2-4f2d-8095-251ffb6985a5"`),
+			"a9959197-b5e2-4f2d-8095-251ffb6985a5",
+			nil,
+		},
+		"name exists": {
+			NewMockJSONRPC().OnError("bdev_lvol_create_lvstore", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			ErrLvstoreExists,
+		},
+		"bdev claimed": {
+			NewMockJSONRPC().OnError("bdev_lvol_create_lvstore", &RPCError{Code: EBUSYCode, Message: "Device or resource busy"}),
+			"",
+			ErrBdevClaimed,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewLvolService(tt.mock).CreateLvstore(context.Background(), "Malloc0", "lvs0", 4194304)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			want := &LvolStoreCreateParams{BdevName: "Malloc0", LvsName: "lvs0", ClusterSz: 4194304}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestLvolService_CreateLvol(t *testing.T) {
+	mock := NewMockJSONRPC().On("bdev_lvol_create", `"1b38702c-7f0c-4e1e-89b5-6d4d2f8bea6b"`)
+	params := LvolParams{LvolName: "lvol0", SizeInMib: 16, LvsName: "lvs0", ThinProvision: true, ClearMethod: "unmap"}
+	got, err := NewLvolService(mock).CreateLvol(context.Background(), params)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if want := "1b38702c-7f0c-4e1e-89b5-6d4d2f8bea6b"; got != want {
+		t.Error("response: expected", want, "received", got)
+	}
+	if args := mock.Calls()[0].Args; !reflect.DeepEqual(args, &params) {
+		t.Error("args: expected", &params, "received", args)
+	}
+}
+
+func TestLvolService_SnapshotLvol(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    string
+		wantErr error
+	}{
+		"created": {
+			NewMockJSONRPC().On("bdev_lvol_snapshot", `"cc8d7fdf-7865-4d1f-9fc6-35da8e368670"`),
+			"cc8d7fdf-7865-4d1f-9fc6-35da8e368670",
+			nil,
+		},
+		"name exists": {
+			NewMockJSONRPC().OnError("bdev_lvol_snapshot", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			ErrLvolExists,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewLvolService(tt.mock).SnapshotLvol(context.Background(), "lvs0/lvol0", "snap0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			want := &LvolSnapshotParams{LvolName: "lvs0/lvol0", SnapshotName: "snap0"}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestLvolService_CloneLvol(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		want    string
+		wantErr error
+	}{
+		"created": {
+			NewMockJSONRPC().On("bdev_lvol_clone", `"6d9b8d0e-b6bf-4b1d-a387-92d1aa89d37e"`),
+			"6d9b8d0e-b6bf-4b1d-a387-92d1aa89d37e",
+			nil,
+		},
+		"name exists": {
+			NewMockJSONRPC().OnError("bdev_lvol_clone", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			"",
+			ErrLvolExists,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewLvolService(tt.mock).CloneLvol(context.Background(), "lvs0/snap0", "clone0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			want := &LvolCloneParams{SnapshotName: "lvs0/snap0", CloneName: "clone0"}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestLvolService_InflateLvol(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"inflated": {
+			NewMockJSONRPC().On("bdev_lvol_inflate", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("bdev_lvol_inflate", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+		"not found": {
+			NewMockJSONRPC().OnError("bdev_lvol_inflate", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewLvolService(tt.mock).InflateLvol(context.Background(), "lvs0/clone0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := &LvolInflateParams{Name: "lvs0/clone0"}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// GetMethods lists every RPC method registered in SPDK
+func (r *Client) GetMethods(ctx context.Context) ([]string, error) {
+	return r.getMethods(ctx, false)
+}
+
+// GetCurrentMethods lists only the RPC methods that can be called in the
+// current SPDK state, e.g. before framework_start_init only startup methods
+func (r *Client) GetCurrentMethods(ctx context.Context) ([]string, error) {
+	return r.getMethods(ctx, true)
+}
+
+// HasMethod reports whether SPDK registers the given RPC method
+func (r *Client) HasMethod(ctx context.Context, name string) (bool, error) {
+	methods, err := r.GetMethods(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, method := range methods {
+		if method == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getMethods calls rpc_get_methods, params are only sent when current is set
+func (r *Client) getMethods(ctx context.Context, current bool) ([]string, error) {
+	var params interface{}
+	if current {
+		params = &RPCGetMethodsParams{Current: true}
+	}
+	var methods []string
+	if err := r.Call(ctx, "rpc_get_methods", params, &methods); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
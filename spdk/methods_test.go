@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSpdk_GetMethods(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	serve(ln, func(req RPCRequest) string {
+		result := `["spdk_get_version","rpc_get_methods","bdev_get_bdevs"]`
+		if params, ok := req.Params.(map[string]interface{}); ok && params["current"] == true {
+			result = `["spdk_get_version","rpc_get_methods"]`
+		}
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+	})
+	ctx := context.Background()
+
+	methods, err := client.GetMethods(ctx)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if want := []string{"spdk_get_version", "rpc_get_methods", "bdev_get_bdevs"}; !reflect.DeepEqual(methods, want) {
+		t.Error("response: expected", want, "received", methods)
+	}
+	current, err := client.GetCurrentMethods(ctx)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if want := []string{"spdk_get_version", "rpc_get_methods"}; !reflect.DeepEqual(current, want) {
+		t.Error("current: expected", want, "received", current)
+	}
+
+	tests := map[string]struct {
+		name string
+		want bool
+	}{
+		"registered":   {"bdev_get_bdevs", true},
+		"unregistered": {"bdev_lvol_set_parent", false},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := client.HasMethod(ctx, tt.name)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
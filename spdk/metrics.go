@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"time"
+)
+
+// MetricsHook is notified of every call Client makes, e.g. to feed
+// Prometheus counters and histograms. ObserveCall runs on the calling
+// goroutine after the call completes, so it must be cheap and safe for
+// concurrent use.
+type MetricsHook interface {
+	ObserveCall(method string, dur time.Duration, err error)
+}
+
+// MetricsHookFunc adapts an ordinary function to MetricsHook
+type MetricsHookFunc func(method string, dur time.Duration, err error)
+
+// build time check that struct implements interface
+var _ MetricsHook = MetricsHookFunc(nil)
+
+// ObserveCall implements MetricsHook by calling f
+func (f MetricsHookFunc) ObserveCall(method string, dur time.Duration, err error) {
+	f(method, dur, err)
+}
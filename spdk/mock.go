@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// MockCall records a single call made through MockJSONRPC
+type MockCall struct {
+	Method string
+	Args   interface{}
+}
+
+// MockJSONRPC is an in-memory JSONRPC for unit tests, it answers every
+// method with the response or error registered for it and records the calls
+type MockJSONRPC struct {
+	mu        sync.Mutex
+	id        uint64
+	responses map[string]json.RawMessage
+	errors    map[string]error
+	calls     []MockCall
+}
+
+// build time check that struct implements interface
+var _ JSONRPC = (*MockJSONRPC)(nil)
+
+// NewMockJSONRPC is a constructor for MockJSONRPC
+func NewMockJSONRPC() *MockJSONRPC {
+	return &MockJSONRPC{
+		responses: make(map[string]json.RawMessage),
+		errors:    make(map[string]error),
+	}
+}
+
+// On registers the result returned for method. A string, []byte or
+// json.RawMessage is taken as raw JSON, any other value is marshaled.
+func (m *MockJSONRPC) On(method string, response interface{}) *MockJSONRPC {
+	var raw json.RawMessage
+	switch response := response.(type) {
+	case string:
+		raw = json.RawMessage(response)
+	case []byte:
+		raw = json.RawMessage(response)
+	case json.RawMessage:
+		raw = response
+	default:
+		data, err := json.Marshal(response)
+		if err != nil {
+			return m.OnError(method, fmt.Errorf("%s: %s", method, err))
+		}
+		raw = data
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.errors, method)
+	m.responses[method] = raw
+	return m
+}
+
+// OnError registers the error returned for method, e.g. an *RPCError
+func (m *MockJSONRPC) OnError(method string, err error) *MockJSONRPC {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.responses, method)
+	m.errors[method] = err
+	return m
+}
+
+// Calls returns all calls made so far, in order
+func (m *MockJSONRPC) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// GetID returns the number of calls made so far
+func (m *MockJSONRPC) GetID() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.id
+}
+
+// GetVersion answers with the version registered for spdk_get_version
+func (m *MockJSONRPC) GetVersion(ctx context.Context) (SpdkVersion, error) {
+	var ver SpdkVersion
+	if err := m.Call(ctx, "spdk_get_version", nil, &ver); err != nil {
+		return SpdkVersion{}, err
+	}
+	return ver, nil
+}
+
+// StartUnixListener returns nil since the mock never touches a socket
+func (m *MockJSONRPC) StartUnixListener() net.Listener {
+	return nil
+}
+
+// Call records the call and answers with what was registered for method,
+// unregistered methods fail with the JSON-RPC method not found error
+func (m *MockJSONRPC) Call(_ context.Context, method string, args, result interface{}) error {
+	m.mu.Lock()
+	m.id++
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+	raw, ok := m.responses[method]
+	err := m.errors[method]
+	m.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RPCError{Method: method, Code: MethodNotFoundCode, Message: "Method not found"}
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return &ResultDecodeError{Method: method, Raw: raw, Err: err}
+	}
+	return nil
+}
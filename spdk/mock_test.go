@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpdk_MockJSONRPC(t *testing.T) {
+	rpcErr := &RPCError{Code: EEXISTCode, Message: "File exists"}
+	mock := NewMockJSONRPC().
+		On("bdev_get_bdevs", `[{"name":"Malloc0","block_size":512}]`).
+		On("spdk_get_version", SpdkVersion{Version: "SPDK v23.01"}).
+		OnError("bdev_malloc_create", rpcErr)
+	ctx := context.Background()
+
+	var bdevs []BdevGetBdevsResult
+	if err := mock.Call(ctx, "bdev_get_bdevs", &BdevGetBdevsParams{Name: "Malloc0"}, &bdevs); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(bdevs) != 1 || bdevs[0].Name != "Malloc0" || bdevs[0].BlockSize != 512 {
+		t.Error("response: unexpected", bdevs)
+	}
+	if version, err := mock.GetVersion(ctx); err != nil || version.Version != "SPDK v23.01" {
+		t.Error("version: expected SPDK v23.01 received", version, err)
+	}
+	var name string
+	if err := mock.Call(ctx, "bdev_malloc_create", nil, &name); !errors.Is(err, rpcErr) {
+		t.Error("error: expected", rpcErr, "received", err)
+	}
+	var unknown *RPCError
+	if err := mock.Call(ctx, "bdev_unknown", nil, nil); !errors.As(err, &unknown) || unknown.Code != MethodNotFoundCode {
+		t.Error("error: expected method not found received", err)
+	}
+
+	expected := []MockCall{
+		{Method: "bdev_get_bdevs", Args: &BdevGetBdevsParams{Name: "Malloc0"}},
+		{Method: "spdk_get_version", Args: nil},
+		{Method: "bdev_malloc_create", Args: nil},
+		{Method: "bdev_unknown", Args: nil},
+	}
+	if calls := mock.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Error("calls: expected", expected, "received", calls)
+	}
+	if id := mock.GetID(); id != 4 {
+		t.Error("id: expected 4 received", id)
+	}
+}
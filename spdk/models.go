@@ -5,6 +5,10 @@
 // Package spdk implements the spdk json-rpc protocol
 package spdk
 
+import (
+	"encoding/json"
+)
+
 const (
 	// TweakModeSimpleLba represents tweak as
 	// Tweak[127:0] = {64'b0, LBA[63:0]}
@@ -54,17 +58,56 @@ type AccelCryptoKeyGetResult struct {
 	Key2   string `json:"key2"`
 }
 
-// GetVersionResult is the result of getting a version
-type GetVersionResult struct {
-	Version string `json:"version"`
-	Fields  struct {
-		Major  int    `json:"major"`
-		Minor  int    `json:"minor"`
-		Patch  int    `json:"patch"`
-		Suffix string `json:"suffix"`
-	} `json:"fields"`
+// AccelModuleInfo is an acceleration module and the operations it supports,
+// as reported by accel_get_module_info
+type AccelModuleInfo struct {
+	Module       string   `json:"module"`
+	SupportedOps []string `json:"supported ops"`
+}
+
+// AccelInfo holds the module assigned to each acceleration operation, keyed
+// by operation name, and the modules that are loaded
+type AccelInfo struct {
+	Assignments map[string]string
+	Modules     []AccelModuleInfo
+}
+
+// AccelAssignOpcParams holds the parameters required to assign an operation to a module
+type AccelAssignOpcParams struct {
+	Opname string `json:"opname"`
+	Module string `json:"module"`
+}
+
+// AccelAssignOpcResult is the result of assigning an operation to a module
+type AccelAssignOpcResult bool
+
+// SpdkVersion is the result of spdk_get_version
+type SpdkVersion struct {
+	Version string            `json:"version"`
+	Fields  SpdkVersionFields `json:"fields"`
+}
+
+// SpdkVersionFields holds the version components reported by SPDK, Commit
+// is only set when SPDK was built from a git checkout
+type SpdkVersionFields struct {
+	Major  int    `json:"major"`
+	Minor  int    `json:"minor"`
+	Patch  int    `json:"patch"`
+	Suffix string `json:"suffix"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// RPCGetMethodsParams holds the parameters of rpc_get_methods, Current limits
+// the list to the methods allowed in the current SPDK state
+type RPCGetMethodsParams struct {
+	Current bool `json:"current,omitempty"`
 }
 
+// GetVersionResult is the result of getting a version
+//
+// Deprecated: use SpdkVersion
+type GetVersionResult = SpdkVersion
+
 // BdevAioCreateParams holds the parameters required to create an AIO Block Device
 type BdevAioCreateParams struct {
 	Name      string `json:"name"`
@@ -83,12 +126,36 @@ type BdevAioDeleteParams struct {
 // BdevAioDeleteResult is the result of deleting an AIO Block Device
 type BdevAioDeleteResult bool
 
+// AioParams holds the parameters required to create an AIO Block Device backed
+// by a file or block device, a zero BlockSize lets SPDK detect it
+type AioParams = BdevAioCreateParams
+
+// UringParams holds the parameters required to create a uring Block Device
+// backed by a file or block device, a zero BlockSize lets SPDK detect it
+type UringParams struct {
+	Name      string `json:"name"`
+	Filename  string `json:"filename"`
+	BlockSize int    `json:"block_size,omitempty"`
+}
+
+// BdevUringCreateResult is the result of creating a uring Block Device
+type BdevUringCreateResult string
+
+// BdevUringDeleteParams holds the parameters required to delete a uring Block Device
+type BdevUringDeleteParams struct {
+	Name string `json:"name"`
+}
+
+// BdevUringDeleteResult is the result of deleting a uring Block Device
+type BdevUringDeleteResult bool
+
 // BdevMalloCreateParams holds the parameters required to create a Malloc Block Device
 type BdevMalloCreateParams struct {
-	NumBlocks int    `json:"num_blocks"`
-	BlockSize int    `json:"block_size"`
-	Name      string `json:"name"`
-	UUID      string `json:"uuid"`
+	NumBlocks         int    `json:"num_blocks"`
+	BlockSize         int    `json:"block_size"`
+	Name              string `json:"name"`
+	UUID              string `json:"uuid"`
+	OptimalIoBoundary int    `json:"optimal_io_boundary,omitempty"`
 }
 
 // BdevAMalloCreateResult is the result of creating a Malloc Block Device
@@ -102,6 +169,9 @@ type BdevMallocDeleteParams struct {
 // BdevMallocDeleteResult is the result of deleting a Malloc Block Device
 type BdevMallocDeleteResult bool
 
+// MallocBdevParams holds the parameters required to create a Malloc Block Device
+type MallocBdevParams = BdevMalloCreateParams
+
 // BdevNullCreateParams holds the parameters required to create a Null Block Device
 // that discards all writes and returns undefined data for reads
 type BdevNullCreateParams struct {
@@ -121,13 +191,136 @@ type BdevNullDeleteParams struct {
 // BdevNullDeleteResult is the result of deleting a Null Block Device
 type BdevNullDeleteResult bool
 
+// CompressParams holds the parameters required to create a Compress Block Device
+// with its metadata in the persistent memory directory PmPath, a zero LbSize
+// keeps the logical block size of the base bdev
+type CompressParams struct {
+	BaseBdevName string `json:"base_bdev_name"`
+	PmPath       string `json:"pm_path"`
+	LbSize       uint32 `json:"lb_size,omitempty"`
+	CompAlgo     string `json:"comp_algo,omitempty"`
+}
+
+// BdevCompressCreateResult is the result of creating a Compress Block Device
+type BdevCompressCreateResult string
+
+// BdevErrorCreateParams holds the parameters required to create an Error Block Device
+type BdevErrorCreateParams struct {
+	BaseName string `json:"base_name"`
+}
+
+// BdevErrorCreateResult is the result of creating an Error Block Device
+type BdevErrorCreateResult bool
+
+// ErrorInjectParams holds the parameters required to inject errors into an
+// Error Block Device. IoType is one of read, write, unmap, flush or all and
+// ErrorType one of failure, pending, corrupt_data or nomem. Num is the number
+// of I/Os to fail, SPDK fails a single one when it is zero, and CorruptOffset
+// and CorruptValue only apply to corrupt_data.
+type ErrorInjectParams struct {
+	Name          string `json:"name"`
+	IoType        string `json:"io_type"`
+	ErrorType     string `json:"error_type"`
+	Num           uint32 `json:"num,omitempty"`
+	CorruptOffset uint64 `json:"corrupt_offset,omitempty"`
+	CorruptValue  uint8  `json:"corrupt_value,omitempty"`
+}
+
+// BdevErrorInjectResult is the result of injecting errors into an Error Block Device
+type BdevErrorInjectResult bool
+
+// BdevPassthruCreateParams holds the parameters required to create a Passthru Block Device
+type BdevPassthruCreateParams struct {
+	BaseBdevName string `json:"base_bdev_name"`
+	Name         string `json:"name"`
+}
+
+// BdevPassthruCreateResult is the result of creating a Passthru Block Device
+type BdevPassthruCreateResult string
+
+// BdevPassthruDeleteParams holds the parameters required to delete a Passthru Block Device
+type BdevPassthruDeleteParams struct {
+	Name string `json:"name"`
+}
+
+// BdevPassthruDeleteResult is the result of deleting a Passthru Block Device
+type BdevPassthruDeleteResult bool
+
+// OcfParams holds the parameters required to create an OCF cache Block Device
+// caching CoreBdevName on CacheBdevName, Mode is one of wt, wb, pt, wa, wi or
+// wo and a zero CacheLineSize, in KiB, keeps the OCF default
+type OcfParams struct {
+	Name          string `json:"name"`
+	Mode          string `json:"mode"`
+	CacheLineSize uint64 `json:"cache_line_size,omitempty"`
+	CacheBdevName string `json:"cache_bdev_name"`
+	CoreBdevName  string `json:"core_bdev_name"`
+}
+
+// BdevOcfCreateResult is the result of creating an OCF Block Device
+type BdevOcfCreateResult string
+
+// BdevOcfDeleteParams holds the parameters required to delete an OCF Block Device
+type BdevOcfDeleteParams struct {
+	Name string `json:"name"`
+}
+
+// BdevOcfDeleteResult is the result of deleting an OCF Block Device
+type BdevOcfDeleteResult bool
+
+// OcfBaseBdev is the cache or core bdev of an OCF Block Device, which is not
+// attached until it exists
+type OcfBaseBdev struct {
+	Name     string `json:"name"`
+	Attached bool   `json:"attached"`
+}
+
+// OcfBdev is an OCF Block Device as reported by bdev_ocf_get_bdevs, it only
+// starts once both its cache and core bdev are attached
+type OcfBdev struct {
+	Name    string      `json:"name"`
+	Started bool        `json:"started"`
+	Cache   OcfBaseBdev `json:"cache"`
+	Core    OcfBaseBdev `json:"core"`
+}
+
+// BdevOcfGetStatsParams holds the parameters required to get the statistics of an OCF Block Device
+type BdevOcfGetStatsParams struct {
+	Name string `json:"name"`
+}
+
+// OcfStat is a single OCF statistic, the percentage is formatted by SPDK
+type OcfStat struct {
+	Count      uint64 `json:"count"`
+	Percentage string `json:"percentage"`
+	Units      string `json:"units"`
+}
+
+// OcfStats holds the statistics of an OCF Block Device by section, e.g. the
+// requests section counts rd_hits, rd_total, wr_hits and wr_total
+type OcfStats struct {
+	Usage    map[string]OcfStat `json:"usage"`
+	Requests map[string]OcfStat `json:"requests"`
+	Blocks   map[string]OcfStat `json:"blocks"`
+	Errors   map[string]OcfStat `json:"errors"`
+}
+
 // BdevCryptoCreateParams holds the parameters required to create a Crypto Block Device
 type BdevCryptoCreateParams struct {
 	BaseBdevName string `json:"base_bdev_name"`
 	Name         string `json:"name"`
-	KeyName      string `json:"key_name"`
+	KeyName      string `json:"key_name,omitempty"`
+	Key          string `json:"key,omitempty"`
+	Key2         string `json:"key2,omitempty"`
+	Cipher       string `json:"cipher,omitempty"`
 }
 
+// CryptoParams holds the parameters required to create a Crypto Block Device,
+// either from a key created with accel_crypto_key_create named by KeyName or,
+// on older SPDK versions, from the hex encoded inline Key and Key2. The key
+// fields are listed in SensitiveFields.
+type CryptoParams = BdevCryptoCreateParams
+
 // BdevCryptoCreateResult is the result of creating a Crypto Block Device
 type BdevCryptoCreateResult string
 
@@ -141,17 +334,25 @@ type BdevCryptoDeleteResult bool
 
 // BdevNvmeAttachControllerParams is the parameters required to create a block device based on an NVMe device
 type BdevNvmeAttachControllerParams struct {
-	Name      string `json:"name"`
-	Trtype    string `json:"trtype"`
-	Traddr    string `json:"traddr"`
-	Hostnqn   string `json:"hostnqn,omitempty"`
-	Adrfam    string `json:"adrfam,omitempty"`
-	Trsvcid   string `json:"trsvcid,omitempty"`
-	Subnqn    string `json:"subnqn,omitempty"`
-	Hdgst     bool   `json:"hdgst,omitempty"`
-	Ddgst     bool   `json:"ddgst,omitempty"`
-	Psk       string `json:"psk,omitempty"`
-	Multipath string `json:"multipath,omitempty"`
+	Name                 string `json:"name"`
+	Trtype               string `json:"trtype"`
+	Traddr               string `json:"traddr"`
+	Hostnqn              string `json:"hostnqn,omitempty"`
+	Adrfam               string `json:"adrfam,omitempty"`
+	Trsvcid              string `json:"trsvcid,omitempty"`
+	Subnqn               string `json:"subnqn,omitempty"`
+	Hdgst                bool   `json:"hdgst,omitempty"`
+	Ddgst                bool   `json:"ddgst,omitempty"`
+	Psk                  string `json:"psk,omitempty"`
+	Multipath            string `json:"multipath,omitempty"`
+	Hostaddr             string `json:"hostaddr,omitempty"`
+	Hostsvcid            string `json:"hostsvcid,omitempty"`
+	PrchkReftag          bool   `json:"prchk_reftag,omitempty"`
+	PrchkGuard           bool   `json:"prchk_guard,omitempty"`
+	NumIoQueues          int    `json:"num_io_queues,omitempty"`
+	CtrlrLossTimeoutSec  int    `json:"ctrlr_loss_timeout_sec,omitempty"`
+	ReconnectDelaySec    int    `json:"reconnect_delay_sec,omitempty"`
+	FastIoFailTimeoutSec int    `json:"fast_io_fail_timeout_sec,omitempty"`
 }
 
 // BdevNvmeAttachControllerResult is the result of creating a block device based on an NVMe device
@@ -170,6 +371,14 @@ type BdevNvmeDetachControllerParams struct {
 // BdevNvmeDetachControllerResult is the result of detaching a block device based on an NVMe device
 type BdevNvmeDetachControllerResult bool
 
+// NvmeAttachParams holds the parameters required to attach an NVMe controller,
+// SPDK creates one bdev per active namespace named after Name
+type NvmeAttachParams = BdevNvmeAttachControllerParams
+
+// NvmeDetachParams holds the parameters required to detach an NVMe controller,
+// the optional address selects a single path of a multipath controller
+type NvmeDetachParams = BdevNvmeDetachControllerParams
+
 // BdevNvmeGetControllerParams is the parameters required to get a block device based on an NVMe device
 type BdevNvmeGetControllerParams struct {
 	Name string `json:"name"`
@@ -177,28 +386,44 @@ type BdevNvmeGetControllerParams struct {
 
 // BdevNvmeGetControllerResult is the result of getting a block device based on an NVMe device
 type BdevNvmeGetControllerResult struct {
-	Name   string `json:"name"`
-	Ctrlrs []struct {
-		State string `json:"state"`
-		Trid  struct {
-			Trtype  string `json:"trtype"`
-			Adrfam  string `json:"adrfam"`
-			Traddr  string `json:"traddr"`
-			Trsvcid string `json:"trsvcid"`
-			Subnqn  string `json:"subnqn"`
-		} `json:"trid"`
-		Cntlid int `json:"cntlid"`
-		Host   struct {
-			Nqn   string `json:"nqn"`
-			Addr  string `json:"addr"`
-			Svcid string `json:"svcid"`
-		} `json:"host"`
-	} `json:"ctrlrs"`
+	Name   string               `json:"name"`
+	Ctrlrs []NvmeControllerPath `json:"ctrlrs"`
+}
+
+// NvmeTransportID identifies the transport address of an NVMe controller path
+type NvmeTransportID struct {
+	Trtype  string `json:"trtype"`
+	Adrfam  string `json:"adrfam"`
+	Traddr  string `json:"traddr"`
+	Trsvcid string `json:"trsvcid"`
+	Subnqn  string `json:"subnqn"`
+}
+
+// NvmeHost identifies the host side of an NVMe controller path
+type NvmeHost struct {
+	Nqn   string `json:"nqn"`
+	Addr  string `json:"addr"`
+	Svcid string `json:"svcid"`
 }
 
+// NvmeControllerPath is a single path of an attached NVMe controller, State is
+// e.g. enabled, resetting, failed or deleting
+type NvmeControllerPath struct {
+	State          string            `json:"state"`
+	Trid           NvmeTransportID   `json:"trid"`
+	Cntlid         int               `json:"cntlid"`
+	Host           NvmeHost          `json:"host"`
+	AlternateTrids []NvmeTransportID `json:"alternate_trids,omitempty"`
+}
+
+// NvmeController is an attached NVMe controller as reported by
+// bdev_nvme_get_controllers, multipath controllers have one entry per path
+type NvmeController = BdevNvmeGetControllerResult
+
 // BdevGetBdevsParams is the parameters required to get a block device
 type BdevGetBdevsParams struct {
-	Name string `json:"name"`
+	Name    string `json:"name"`
+	Timeout int64  `json:"timeout,omitempty"`
 }
 
 // BdevGetBdevsResult is the result of getting a block device
@@ -209,6 +434,79 @@ type BdevGetBdevsResult struct {
 	UUID      string `json:"uuid"`
 }
 
+// Bdev is a block device as reported by bdev_get_bdevs
+type Bdev struct {
+	Name             string                     `json:"name"`
+	Aliases          []string                   `json:"aliases"`
+	ProductName      string                     `json:"product_name"`
+	BlockSize        int64                      `json:"block_size"`
+	NumBlocks        int64                      `json:"num_blocks"`
+	UUID             string                     `json:"uuid"`
+	Claimed          bool                       `json:"claimed"`
+	DriverSpecific   map[string]json.RawMessage `json:"driver_specific"`
+	SupportedIoTypes map[string]bool            `json:"supported_io_types"`
+	// AssignedRateLimits holds the QoS limits set with bdev_set_qos_limit
+	AssignedRateLimits QoSLimits `json:"assigned_rate_limits"`
+}
+
+// QoSLimits holds the rate limits of a block device, zero means no limit. All
+// of them are always sent, so setting limits clears the ones left at zero.
+type QoSLimits struct {
+	RwIosPerSec    int `json:"rw_ios_per_sec"`
+	RwMbytesPerSec int `json:"rw_mbytes_per_sec"`
+	RMbytesPerSec  int `json:"r_mbytes_per_sec"`
+	WMbytesPerSec  int `json:"w_mbytes_per_sec"`
+}
+
+// BdevExamineParams holds the parameters required to examine a block device
+type BdevExamineParams struct {
+	Name string `json:"name"`
+}
+
+// BdevExamineResult is the result of examining a block device
+type BdevExamineResult bool
+
+// BdevWaitForExamineResult is the result of waiting for the bdev examination to finish
+type BdevWaitForExamineResult bool
+
+// BdevEnableHistogramParams holds the parameters required to enable or disable
+// the latency histogram of a Block Device
+type BdevEnableHistogramParams struct {
+	Name   string `json:"name"`
+	Enable bool   `json:"enable"`
+}
+
+// BdevEnableHistogramResult is the result of enabling or disabling a latency histogram
+type BdevEnableHistogramResult bool
+
+// BdevGetHistogramParams holds the parameters required to get the latency
+// histogram of a Block Device
+type BdevGetHistogramParams struct {
+	Name string `json:"name"`
+}
+
+// BdevGetHistogramResult is the latency histogram of a Block Device as SPDK
+// sends it, the bucket counters are base64 encoded
+type BdevGetHistogramResult struct {
+	Histogram   string `json:"histogram"`
+	TscRate     uint64 `json:"tsc_rate"`
+	BucketShift uint32 `json:"bucket_shift"`
+}
+
+// BdevOpts holds the options of the bdev layer, zero values are omitted so
+// SPDK keeps its defaults. AutoExamine is a pointer since false is the value
+// that matters.
+type BdevOpts struct {
+	BdevIoPoolSize   uint32 `json:"bdev_io_pool_size,omitempty"`
+	BdevIoCacheSize  uint32 `json:"bdev_io_cache_size,omitempty"`
+	AutoExamine      *bool  `json:"bdev_auto_examine,omitempty"`
+	SmallBufPoolSize uint32 `json:"small_buf_pool_size,omitempty"`
+	LargeBufPoolSize uint32 `json:"large_buf_pool_size,omitempty"`
+}
+
+// BdevSetOptionsResult is the result of setting the options of the bdev layer
+type BdevSetOptionsResult bool
+
 // BdevGetIostatParams hold the parameters required to get the IO stats of a block device
 type BdevGetIostatParams struct {
 	Name string `json:"name"`
@@ -216,22 +514,33 @@ type BdevGetIostatParams struct {
 
 // BdevGetIostatResult hold the results of getting the IO stats of a block device
 type BdevGetIostatResult struct {
-	TickRate int   `json:"tick_rate"`
-	Ticks    int64 `json:"ticks"`
-	Bdevs    []struct {
-		Name              string `json:"name"`
-		BytesRead         int    `json:"bytes_read"`
-		NumReadOps        int    `json:"num_read_ops"`
-		BytesWritten      int    `json:"bytes_written"`
-		NumWriteOps       int    `json:"num_write_ops"`
-		BytesUnmapped     int    `json:"bytes_unmapped"`
-		NumUnmapOps       int    `json:"num_unmap_ops"`
-		ReadLatencyTicks  int    `json:"read_latency_ticks"`
-		WriteLatencyTicks int    `json:"write_latency_ticks"`
-		UnmapLatencyTicks int    `json:"unmap_latency_ticks"`
-	} `json:"bdevs"`
+	TickRate int          `json:"tick_rate"`
+	Ticks    int64        `json:"ticks"`
+	Bdevs    []BdevIostat `json:"bdevs"`
+}
+
+// BdevIostat holds the IO statistics SPDK reports for a single block device,
+// the queue depth fields are only populated when queue depth sampling is enabled
+type BdevIostat struct {
+	Name                    string `json:"name"`
+	BytesRead               int    `json:"bytes_read"`
+	NumReadOps              int    `json:"num_read_ops"`
+	BytesWritten            int    `json:"bytes_written"`
+	NumWriteOps             int    `json:"num_write_ops"`
+	BytesUnmapped           int    `json:"bytes_unmapped"`
+	NumUnmapOps             int    `json:"num_unmap_ops"`
+	ReadLatencyTicks        int    `json:"read_latency_ticks"`
+	WriteLatencyTicks       int    `json:"write_latency_ticks"`
+	UnmapLatencyTicks       int    `json:"unmap_latency_ticks"`
+	QueueDepthPollingPeriod int    `json:"queue_depth_polling_period,omitempty"`
+	QueueDepth              int    `json:"queue_depth,omitempty"`
+	IoTime                  int    `json:"io_time,omitempty"`
+	WeightedIoTime          int    `json:"weighted_io_time,omitempty"`
 }
 
+// IostatResult holds the results of bdev_get_iostat
+type IostatResult = BdevGetIostatResult
+
 // BdevQoSParams holds the parameters required to set QoS on a Block Device
 type BdevQoSParams struct {
 	Name           string `json:"name"`
@@ -247,8 +556,10 @@ type BdevQoSResult bool
 // VhostCreateBlkControllerParams holds the parameters required to create a block device
 // from a vhost controller
 type VhostCreateBlkControllerParams struct {
-	Ctrlr   string `json:"ctrlr"`
-	DevName string `json:"dev_name"`
+	Ctrlr    string `json:"ctrlr"`
+	DevName  string `json:"dev_name"`
+	Cpumask  string `json:"cpumask,omitempty"`
+	Readonly bool   `json:"readonly,omitempty"`
 }
 
 // VhostCreateBlkControllerResult is the result of creating a block device from a vhost controller
@@ -282,6 +593,12 @@ type VhostGetControllersResult struct {
 	} `json:"backend_specific"`
 }
 
+// VhostBlkParams holds the parameters required to create a vhost-user block controller
+type VhostBlkParams = VhostCreateBlkControllerParams
+
+// VhostController is a vhost controller as reported by vhost_get_controllers
+type VhostController = VhostGetControllersResult
+
 // VhostCreateScsiControllerParams holds the parameters required to create a SCSI controller
 type VhostCreateScsiControllerParams struct {
 	Ctrlr string `json:"ctrlr"`
@@ -292,16 +609,51 @@ type VhostCreateScsiControllerResult bool
 
 // NvmfSubsystemAddNsParams holds the parameters required to add a namespace to an existing subsystem
 type NvmfSubsystemAddNsParams struct {
-	Nqn       string `json:"nqn"`
-	Namespace struct {
-		Nsid     int    `json:"nsid"`
-		BdevName string `json:"bdev_name"`
-	} `json:"namespace"`
+	Nqn       string       `json:"nqn"`
+	Namespace NvmfNsParams `json:"namespace"`
 }
 
 // NvmfSubsystemAddNsResult is the result NSID of attaching a namespace to an existing subsystem
 type NvmfSubsystemAddNsResult int
 
+// NvmfNsParams describes a namespace to attach to an NVMf subsystem, a zero
+// Nsid lets SPDK pick the lowest free one
+type NvmfNsParams struct {
+	Nsid     int    `json:"nsid"`
+	BdevName string `json:"bdev_name"`
+	UUID     string `json:"uuid,omitempty"`
+	Nguid    string `json:"nguid,omitempty"`
+	Eui64    string `json:"eui64,omitempty"`
+}
+
+// NvmfTransportParams holds the parameters required to create an NVMf transport,
+// SPDK applies its own defaults to the options left zero
+type NvmfTransportParams struct {
+	Trtype              string `json:"trtype"`
+	MaxQueueDepth       int    `json:"max_queue_depth,omitempty"`
+	MaxIoQpairsPerCtrlr int    `json:"max_io_qpairs_per_ctrlr,omitempty"`
+	InCapsuleDataSize   int    `json:"in_capsule_data_size,omitempty"`
+	MaxIoSize           int    `json:"max_io_size,omitempty"`
+	IoUnitSize          int    `json:"io_unit_size,omitempty"`
+	NumSharedBuffers    int    `json:"num_shared_buffers,omitempty"`
+}
+
+// NvmfCreateTransportResult is the result of creating an NVMf transport
+type NvmfCreateTransportResult bool
+
+// NvmfTransport is an NVMf transport as reported by nvmf_get_transports
+type NvmfTransport struct {
+	Trtype              string `json:"trtype"`
+	MaxQueueDepth       int    `json:"max_queue_depth"`
+	MaxIoQpairsPerCtrlr int    `json:"max_io_qpairs_per_ctrlr"`
+	InCapsuleDataSize   int    `json:"in_capsule_data_size"`
+	MaxIoSize           int    `json:"max_io_size"`
+	IoUnitSize          int    `json:"io_unit_size"`
+	MaxAqDepth          int    `json:"max_aq_depth"`
+	NumSharedBuffers    int    `json:"num_shared_buffers"`
+	BufCacheSize        int    `json:"buf_cache_size"`
+}
+
 // NvmfSubsystemRemoveNsParams holds the parameters required to Delete a NVMf subsystem
 type NvmfSubsystemRemoveNsParams struct {
 	Nqn  string `json:"nqn"`
@@ -349,6 +701,43 @@ type NvmfGetSubsystemsResult struct {
 	} `json:"namespaces,omitempty"`
 }
 
+// NvmfListenAddress is the transport address an NVMf subsystem listens on
+type NvmfListenAddress struct {
+	Trtype  string `json:"trtype"`
+	Traddr  string `json:"traddr"`
+	Trsvcid string `json:"trsvcid,omitempty"`
+	Adrfam  string `json:"adrfam,omitempty"`
+}
+
+// NvmfHost is a host allowed to connect to an NVMf subsystem
+type NvmfHost struct {
+	Nqn string `json:"nqn"`
+}
+
+// NvmfNamespace is a namespace attached to an NVMf subsystem
+type NvmfNamespace struct {
+	Nsid     int    `json:"nsid"`
+	BdevName string `json:"bdev_name"`
+	Name     string `json:"name,omitempty"`
+	Nguid    string `json:"nguid,omitempty"`
+	UUID     string `json:"uuid,omitempty"`
+}
+
+// NvmfSubsystem is an NVMf subsystem as reported by nvmf_get_subsystems
+type NvmfSubsystem struct {
+	Nqn             string              `json:"nqn"`
+	Subtype         string              `json:"subtype"`
+	ListenAddresses []NvmfListenAddress `json:"listen_addresses"`
+	AllowAnyHost    bool                `json:"allow_any_host"`
+	Hosts           []NvmfHost          `json:"hosts"`
+	SerialNumber    string              `json:"serial_number,omitempty"`
+	ModelNumber     string              `json:"model_number,omitempty"`
+	MaxNamespaces   int                 `json:"max_namespaces,omitempty"`
+	MinCntlid       int                 `json:"min_cntlid,omitempty"`
+	MaxCntlid       int                 `json:"max_cntlid,omitempty"`
+	Namespaces      []NvmfNamespace     `json:"namespaces,omitempty"`
+}
+
 // NvmfGetSubsystemStatsResult is the result of NVMf subsystem statistics
 type NvmfGetSubsystemStatsResult struct {
 	TickRate   int `json:"tick_rate"`
@@ -367,14 +756,9 @@ type NvmfGetSubsystemStatsResult struct {
 
 // NvmfSubsystemAddListenerParams holds the parameters required to Delete a NVMf subsystem
 type NvmfSubsystemAddListenerParams struct {
-	Nqn           string `json:"nqn"`
-	SecureChannel bool   `json:"secure_channel,omitempty"`
-	ListenAddress struct {
-		Trtype  string `json:"trtype"`
-		Traddr  string `json:"traddr"`
-		Trsvcid string `json:"trsvcid,omitempty"`
-		Adrfam  string `json:"adrfam,omitempty"`
-	} `json:"listen_address"`
+	Nqn           string            `json:"nqn"`
+	SecureChannel bool              `json:"secure_channel,omitempty"`
+	ListenAddress NvmfListenAddress `json:"listen_address"`
 }
 
 // NvmfSubsystemAddListenerResult is the result of creating a NVMf subsystem
@@ -390,3 +774,281 @@ type NvmfSubsystemAddHostParams struct {
 
 // NvmfSubsystemAddHostResult is the result of adding host to NVMf subsystem
 type NvmfSubsystemAddHostResult bool
+
+// LvolStoreCreateParams holds the parameters required to create an lvol store
+type LvolStoreCreateParams struct {
+	BdevName  string `json:"bdev_name"`
+	LvsName   string `json:"lvs_name"`
+	ClusterSz uint64 `json:"cluster_sz,omitempty"`
+}
+
+// LvolStoreCreateResult is the UUID of the created lvol store
+type LvolStoreCreateResult string
+
+// LvolParams holds the parameters required to create a logical volume,
+// the lvol store is selected either by LvsName or by UUID
+type LvolParams struct {
+	LvolName      string `json:"lvol_name"`
+	SizeInMib     uint64 `json:"size_in_mib"`
+	LvsName       string `json:"lvs_name,omitempty"`
+	UUID          string `json:"uuid,omitempty"`
+	ThinProvision bool   `json:"thin_provision,omitempty"`
+	ClearMethod   string `json:"clear_method,omitempty"`
+}
+
+// LvolCreateResult is the name of the bdev created for the logical volume
+type LvolCreateResult string
+
+// LvolSnapshotParams holds the parameters required to snapshot a logical volume
+type LvolSnapshotParams struct {
+	LvolName     string `json:"lvol_name"`
+	SnapshotName string `json:"snapshot_name"`
+}
+
+// LvolSnapshotResult is the UUID of the created snapshot
+type LvolSnapshotResult string
+
+// LvolCloneParams holds the parameters required to clone a snapshot
+type LvolCloneParams struct {
+	SnapshotName string `json:"snapshot_name"`
+	CloneName    string `json:"clone_name"`
+}
+
+// LvolCloneResult is the UUID of the created clone
+type LvolCloneResult string
+
+// LvolInflateParams holds the parameters required to inflate a logical volume
+type LvolInflateParams struct {
+	Name string `json:"name"`
+}
+
+// LvolInflateResult is the result of inflating a logical volume
+type LvolInflateResult bool
+
+// RaidParams holds the parameters required to create a RAID Block Device
+type RaidParams struct {
+	Name        string   `json:"name"`
+	RaidLevel   string   `json:"raid_level"`
+	BaseBdevs   []string `json:"base_bdevs"`
+	StripSizeKb int      `json:"strip_size_kb,omitempty"`
+}
+
+// BdevRaidCreateResult is the result of creating a RAID Block Device
+type BdevRaidCreateResult bool
+
+// BdevRaidGetBdevsParams holds the parameters required to list RAID Block Devices
+type BdevRaidGetBdevsParams struct {
+	Category string `json:"category"`
+}
+
+// BdevRaidDeleteParams holds the parameters required to delete a RAID Block Device
+type BdevRaidDeleteParams struct {
+	Name string `json:"name"`
+}
+
+// BdevRaidDeleteResult is the result of deleting a RAID Block Device
+type BdevRaidDeleteResult bool
+
+// SpdkKillInstanceParams holds the parameters required to stop SPDK
+type SpdkKillInstanceParams struct {
+	SigName string `json:"sig_name"`
+}
+
+// SpdkKillInstanceResult is the result of stopping SPDK
+type SpdkKillInstanceResult bool
+
+// Subsystem is an SPDK framework subsystem as reported by framework_get_subsystems
+type Subsystem struct {
+	Subsystem string   `json:"subsystem"`
+	DependsOn []string `json:"depends_on"`
+}
+
+// FrameworkStartInitResult is the result of starting the framework initialization
+type FrameworkStartInitResult bool
+
+// FrameworkMonitorContextSwitchParams holds the parameters required to enable
+// or disable monitoring the context switches of the reactors
+type FrameworkMonitorContextSwitchParams struct {
+	Enabled bool `json:"enabled"`
+}
+
+// FrameworkMonitorContextSwitchResult is the state of context switch monitoring
+type FrameworkMonitorContextSwitchResult struct {
+	Enabled bool `json:"enabled"`
+}
+
+// FrameworkSetSchedulerParams holds the parameters required to switch the scheduler,
+// a zero Period keeps the current scheduling period
+type FrameworkSetSchedulerParams struct {
+	Name   string `json:"name"`
+	Period uint64 `json:"period,omitempty"`
+}
+
+// FrameworkSetSchedulerResult is the result of switching the scheduler
+type FrameworkSetSchedulerResult bool
+
+// SchedulerInfo is the scheduler in use as reported by framework_get_scheduler,
+// the period is in microseconds and the governor is only reported while one
+// is in use, e.g. by the dynamic scheduler
+type SchedulerInfo struct {
+	SchedulerName   string `json:"scheduler_name"`
+	SchedulerPeriod uint64 `json:"scheduler_period"`
+	GovernorName    string `json:"governor_name,omitempty"`
+}
+
+// FrameworkGetConfigParams holds the parameters required to get the configuration of a subsystem
+type FrameworkGetConfigParams struct {
+	Name string `json:"name"`
+}
+
+// ConfigEntry is a single RPC call that recreates part of a subsystem configuration
+type ConfigEntry struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// SubsystemConfig holds the configuration of a single subsystem, as saved by SaveConfig
+type SubsystemConfig struct {
+	Subsystem string          `json:"subsystem"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// SpdkConfig is the configuration of all subsystems, in the format of the
+// SPDK JSON config file
+type SpdkConfig struct {
+	Subsystems []SubsystemConfig `json:"subsystems"`
+}
+
+// IscsiPgIgMap maps an iSCSI portal group to an initiator group
+type IscsiPgIgMap struct {
+	PgTag int `json:"pg_tag"`
+	IgTag int `json:"ig_tag"`
+}
+
+// IscsiLun exposes a bdev as a LUN of an iSCSI target node
+type IscsiLun struct {
+	BdevName string `json:"bdev_name"`
+	LunID    int    `json:"lun_id"`
+}
+
+// IscsiTargetParams holds the parameters required to create an iSCSI target node
+type IscsiTargetParams struct {
+	Name         string         `json:"name"`
+	AliasName    string         `json:"alias_name"`
+	PgIgMaps     []IscsiPgIgMap `json:"pg_ig_maps"`
+	Luns         []IscsiLun     `json:"luns"`
+	QueueDepth   int            `json:"queue_depth"`
+	DisableChap  bool           `json:"disable_chap,omitempty"`
+	RequireChap  bool           `json:"require_chap,omitempty"`
+	MutualChap   bool           `json:"mutual_chap,omitempty"`
+	ChapGroup    int            `json:"chap_group,omitempty"`
+	HeaderDigest bool           `json:"header_digest,omitempty"`
+	DataDigest   bool           `json:"data_digest,omitempty"`
+}
+
+// IscsiCreateTargetNodeResult is the result of creating an iSCSI target node
+type IscsiCreateTargetNodeResult bool
+
+// IscsiTargetNode is an iSCSI target node as reported by iscsi_get_target_nodes
+type IscsiTargetNode struct {
+	Name         string         `json:"name"`
+	AliasName    string         `json:"alias_name"`
+	PgIgMaps     []IscsiPgIgMap `json:"pg_ig_maps"`
+	Luns         []IscsiLun     `json:"luns"`
+	QueueDepth   int            `json:"queue_depth"`
+	DisableChap  bool           `json:"disable_chap"`
+	RequireChap  bool           `json:"require_chap"`
+	MutualChap   bool           `json:"mutual_chap"`
+	ChapGroup    int            `json:"chap_group"`
+	HeaderDigest bool           `json:"header_digest"`
+	DataDigest   bool           `json:"data_digest"`
+}
+
+// IscsiDeleteTargetNodeParams holds the parameters required to delete an iSCSI target node
+type IscsiDeleteTargetNodeParams struct {
+	Name string `json:"name"`
+}
+
+// IscsiDeleteTargetNodeResult is the result of deleting an iSCSI target node
+type IscsiDeleteTargetNodeResult bool
+
+// LogSetLevelParams holds the parameters required to set the SPDK log level
+type LogSetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// LogSetLevelResult is the result of setting the SPDK log level
+type LogSetLevelResult bool
+
+// LogFlagParams holds the parameters required to set or clear an SPDK log flag
+type LogFlagParams struct {
+	Flag string `json:"flag"`
+}
+
+// LogFlagResult is the result of setting or clearing an SPDK log flag
+type LogFlagResult bool
+
+// NbdStartDiskParams holds the parameters required to export a block device
+// through an NBD device, an empty NbdDevice lets SPDK pick a free one
+type NbdStartDiskParams struct {
+	BdevName  string `json:"bdev_name"`
+	NbdDevice string `json:"nbd_device,omitempty"`
+}
+
+// NbdStartDiskResult is the path of the NBD device the block device is exported through
+type NbdStartDiskResult string
+
+// NbdStopDiskParams holds the parameters required to stop exporting through an NBD device
+type NbdStopDiskParams struct {
+	NbdDevice string `json:"nbd_device"`
+}
+
+// NbdStopDiskResult is the result of stopping an NBD device
+type NbdStopDiskResult bool
+
+// NbdDisk is a block device exported through an NBD device, as reported by nbd_get_disks
+type NbdDisk struct {
+	NbdDevice string `json:"nbd_device"`
+	BdevName  string `json:"bdev_name"`
+}
+
+// ThreadStat holds the statistics of a single SPDK thread
+type ThreadStat struct {
+	Name               string `json:"name"`
+	ID                 uint64 `json:"id"`
+	Cpumask            string `json:"cpumask"`
+	Busy               uint64 `json:"busy"`
+	Idle               uint64 `json:"idle"`
+	ActivePollersCount int    `json:"active_pollers_count"`
+	TimedPollersCount  int    `json:"timed_pollers_count"`
+	PausedPollersCount int    `json:"paused_pollers_count"`
+}
+
+// ThreadStats holds the results of thread_get_stats, busy and idle are in ticks
+type ThreadStats struct {
+	TickRate uint64       `json:"tick_rate"`
+	Threads  []ThreadStat `json:"threads"`
+}
+
+// ReactorThread is an SPDK thread scheduled on a reactor, Elapsed is in ticks
+type ReactorThread struct {
+	Name    string `json:"name"`
+	ID      uint64 `json:"id"`
+	Cpumask string `json:"cpumask"`
+	Elapsed uint64 `json:"elapsed"`
+}
+
+// Reactor holds the utilization of a single core and the threads assigned to it
+type Reactor struct {
+	Lcore       int             `json:"lcore"`
+	Busy        uint64          `json:"busy"`
+	Idle        uint64          `json:"idle"`
+	InInterrupt bool            `json:"in_interrupt"`
+	LwThreads   []ReactorThread `json:"lw_threads"`
+}
+
+// ReactorStats holds the results of framework_get_reactors, busy and idle are in ticks
+type ReactorStats struct {
+	TickRate uint64    `json:"tick_rate"`
+	Reactors []Reactor `json:"reactors"`
+}
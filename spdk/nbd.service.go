@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// NbdService is interface to all NBD export functions in spdk
+type NbdService interface {
+	StartNbdDisk(ctx context.Context, bdevName, nbdDevice string) (string, error)
+	StopNbdDisk(ctx context.Context, nbdDevice string) error
+	GetNbdDisks(ctx context.Context) ([]NbdDisk, error)
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NbdServiceImpl implements NbdService interface
+type NbdServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ NbdService = (*NbdServiceImpl)(nil)
+
+// NewNbdService is a constructor for NbdServiceImpl
+func NewNbdService(client JSONRPC) *NbdServiceImpl {
+	return &NbdServiceImpl{client}
+}
+
+// StartNbdDisk exports the block device to the kernel through nbdDevice, e.g.
+// /dev/nbd0, or through a free NBD device SPDK picks when nbdDevice is empty,
+// and returns the path of the device used. A device that is already in use is
+// reported as ErrNbdDeviceBusy and a missing bdev as ErrBdevNotFound.
+func (p *NbdServiceImpl) StartNbdDisk(ctx context.Context, bdevName, nbdDevice string) (string, error) {
+	if bdevName == "" {
+		return "", status.Error(codes.InvalidArgument, "missing bdev name to export through NBD")
+	}
+	params := NbdStartDiskParams{
+		BdevName:  bdevName,
+		NbdDevice: nbdDevice,
+	}
+	var result NbdStartDiskResult
+	err := p.client.Call(ctx, "nbd_start_disk", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		err = wrapRPCError(err, ErrNbdDeviceBusy, EBUSYCode)
+		return "", wrapRPCError(err, ErrBdevNotFound, ENODEVCode)
+	}
+	return string(result), nil
+}
+
+// StopNbdDisk stops exporting through the NBD device, e.g. /dev/nbd0
+func (p *NbdServiceImpl) StopNbdDisk(ctx context.Context, nbdDevice string) error {
+	if nbdDevice == "" {
+		return status.Error(codes.InvalidArgument, "missing NBD device to stop")
+	}
+	params := NbdStopDiskParams{
+		NbdDevice: nbdDevice,
+	}
+	var result NbdStopDiskResult
+	err := p.client.Call(ctx, "nbd_stop_disk", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not stop NBD device: %s", nbdDevice)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetNbdDisks lists the block devices exported through NBD devices
+func (p *NbdServiceImpl) GetNbdDisks(ctx context.Context) ([]NbdDisk, error) {
+	var result []NbdDisk
+	err := p.client.Call(ctx, "nbd_get_disks", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNbdService_StartNbdDisk(t *testing.T) {
+	tests := map[string]struct {
+		nbdDevice string
+		mock      *MockJSONRPC
+		want      string
+		wantCode  codes.Code
+		wantErr   error
+	}{
+		"requested device": {
+			"/dev/nbd1",
+			NewMockJSONRPC().On("nbd_start_disk", `"/dev/nbd1"`),
+			"/dev/nbd1",
+			codes.OK,
+			nil,
+		},
+		"picked by spdk": {
+			"",
+			NewMockJSONRPC().On("nbd_start_disk", `"/dev/nbd0"`),
+			"/dev/nbd0",
+			codes.OK,
+			nil,
+		},
+		"device busy": {
+			"/dev/nbd1",
+			NewMockJSONRPC().OnError("nbd_start_disk", &RPCError{Code: EBUSYCode, Message: "Device or resource busy"}),
+			"",
+			codes.Unavailable,
+			ErrNbdDeviceBusy,
+		},
+		"bdev not found": {
+			"",
+			NewMockJSONRPC().OnError("nbd_start_disk", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			"",
+			codes.NotFound,
+			ErrBdevNotFound,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewNbdService(tt.mock).StartNbdDisk(context.Background(), "Malloc0", tt.nbdDevice)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			want := []MockCall{{Method: "nbd_start_disk", Args: &NbdStartDiskParams{BdevName: "Malloc0", NbdDevice: tt.nbdDevice}}}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestNbdService_StopNbdDisk(t *testing.T) {
+	tests := map[string]struct {
+		nbdDevice string
+		mock      *MockJSONRPC
+		wantCode  codes.Code
+	}{
+		"stopped": {
+			"/dev/nbd0",
+			NewMockJSONRPC().On("nbd_stop_disk", true),
+			codes.OK,
+		},
+		"unexpected result": {
+			"/dev/nbd0",
+			NewMockJSONRPC().On("nbd_stop_disk", false),
+			codes.FailedPrecondition,
+		},
+		"missing device": {
+			"",
+			NewMockJSONRPC(),
+			codes.InvalidArgument,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewNbdService(tt.mock).StopNbdDisk(context.Background(), tt.nbdDevice)
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			var want []MockCall
+			if tt.nbdDevice != "" {
+				want = []MockCall{{Method: "nbd_stop_disk", Args: &NbdStopDiskParams{NbdDevice: tt.nbdDevice}}}
+			}
+			if calls := tt.mock.Calls(); !reflect.DeepEqual(calls, want) {
+				t.Error("calls: expected", want, "received", calls)
+			}
+		})
+	}
+}
+
+func TestNbdService_GetNbdDisks(t *testing.T) {
+	mock := NewMockJSONRPC().On("nbd_get_disks", `[{"nbd_device":"/dev/nbd0","bdev_name":"Malloc0"}]`)
+	got, err := NewNbdService(mock).GetNbdDisks(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []NbdDisk{{NbdDevice: "/dev/nbd0", BdevName: "Malloc0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Notify sends a JSON-RPC 2.0 notification, a request without an id, and
+// returns once it is written. No response is read or validated: SPDK may
+// answer nothing at all, and anything it does send back is discarded.
+func (r *Client) Notify(ctx context.Context, method string, args interface{}) error {
+	if method == "" {
+		return errEmptyMethod
+	}
+	if err := validateParams(method, args); err != nil {
+		return err
+	}
+	request := RPCRequest{
+		RPCVersion: r.rpcVersion,
+		Method:     method,
+		Params:     omitNilParams(args),
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("%s: %s", method, err)
+	}
+
+	if !r.noRequestLog {
+		r.logger.Printf("Sending to SPDK method=%s: %s", method, r.redact(data))
+	}
+
+	if err := r.send(ctx, data); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+// send writes a request that expects no response. Only the multiplexed
+// connection can absorb a stray reply, so other modes use a one-shot connection.
+func (r *Client) send(ctx context.Context, buf []byte) error {
+	release, err := r.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if r.multiplex {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		m, err := r.muxLocked(ctx)
+		if err != nil {
+			return err
+		}
+		deadline, _ := r.deadline(ctx)
+		if err := m.conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+		if _, err := m.conn.Write(buf); err != nil {
+			_ = m.conn.Close()
+			return transportError(ctx, err)
+		}
+		return nil
+	}
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := r.deadline(ctx); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.Write(buf); err != nil {
+		return transportError(ctx, err)
+	}
+	return nil
+}
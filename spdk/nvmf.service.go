@@ -18,4 +18,13 @@ type NvmfService interface {
 	RemoveListener(context.Context, *NvmfSubsystemAddListenerParams) (*NvmfSubsystemAddListenerResult, error)
 	AddNamespace(context.Context, *NvmfSubsystemAddNsParams) (*NvmfSubsystemAddNsResult, error)
 	RemoveNamespace(context.Context, *NvmfSubsystemRemoveNsParams) (*NvmfSubsystemRemoveNsResult, error)
+
+	CreateNvmfSubsystem(ctx context.Context, params NvmfCreateSubsystemParams) error
+	GetNvmfSubsystems(ctx context.Context) ([]NvmfSubsystem, error)
+	AddNvmfListener(ctx context.Context, nqn string, addr NvmfListenAddress) error
+	RemoveNvmfListener(ctx context.Context, nqn string, addr NvmfListenAddress) error
+	CreateNvmfTransport(ctx context.Context, params NvmfTransportParams) error
+	GetNvmfTransports(ctx context.Context) ([]NvmfTransport, error)
+	AddNvmfNamespace(ctx context.Context, nqn string, params NvmfNsParams) (uint32, error)
+	RemoveNvmfNamespace(ctx context.Context, nqn string, nsid uint32) error
 }
@@ -6,10 +6,24 @@ package spdk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// nvmfListenerTransports lists the transport types a listener can use
+var nvmfListenerTransports = []string{"TCP", "RDMA", "PCIE"}
+
+// nvmfTransports lists the transport types an NVMf target can be created with
+var nvmfTransports = []string{"TCP", "RDMA", "FC", "VFIOUSER"}
+
+// nvmfAddressFamilies lists the address families a listener can use
+var nvmfAddressFamilies = []string{"IPv4", "IPv6"}
+
 // NvmfServiceImpl implements NvmfService interface
 type NvmfServiceImpl struct {
 	client JSONRPC
@@ -19,9 +33,8 @@ type NvmfServiceImpl struct {
 var _ NvmfService = (*NvmfServiceImpl)(nil)
 
 // NewNvmfService is a constructor for NvmfServiceImpl
-func NewNvmfService() *NvmfServiceImpl {
-	// client := spdk.NewClient(spdkAddress)
-	return &NvmfServiceImpl{nil}
+func NewNvmfService(client JSONRPC) *NvmfServiceImpl {
+	return &NvmfServiceImpl{client}
 }
 
 // CreateSubsystem creates nvme subsystem
@@ -90,3 +103,228 @@ func (p *NvmfServiceImpl) RemoveNamespace(context.Context, *NvmfSubsystemRemoveN
 	// TBD
 	return nil, nil
 }
+
+// CreateNvmfSubsystem creates nvme subsystem. SPDK reports an NQN that is
+// already in use as an internal error, so that error is checked against the
+// existing subsystems and reported as ErrNvmfSubsystemExists.
+func (p *NvmfServiceImpl) CreateNvmfSubsystem(ctx context.Context, params NvmfCreateSubsystemParams) error {
+	var result NvmfCreateSubsystemResult
+	err := p.client.Call(ctx, "nvmf_create_subsystem", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && p.hasSubsystem(ctx, params.Nqn) {
+			return &sentinelError{sentinel: ErrNvmfSubsystemExists, err: err}
+		}
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create NQN: %s", params.Nqn)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetNvmfSubsystems lists all nvme subsystems
+func (p *NvmfServiceImpl) GetNvmfSubsystems(ctx context.Context) ([]NvmfSubsystem, error) {
+	var result []NvmfSubsystem
+	err := p.client.Call(ctx, "nvmf_get_subsystems", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// hasSubsystem reports whether a subsystem with the given NQN exists,
+// a failure to list them counts as not existing
+func (p *NvmfServiceImpl) hasSubsystem(ctx context.Context, nqn string) bool {
+	subsystems, err := p.GetNvmfSubsystems(ctx)
+	if err != nil {
+		return false
+	}
+	for _, subsystem := range subsystems {
+		if subsystem.Nqn == nqn {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNvmfListener adds nvme listener
+func (p *NvmfServiceImpl) AddNvmfListener(ctx context.Context, nqn string, addr NvmfListenAddress) error {
+	return p.callListener(ctx, "nvmf_subsystem_add_listener", nqn, addr)
+}
+
+// RemoveNvmfListener removes nvme listener
+func (p *NvmfServiceImpl) RemoveNvmfListener(ctx context.Context, nqn string, addr NvmfListenAddress) error {
+	return p.callListener(ctx, "nvmf_subsystem_remove_listener", nqn, addr)
+}
+
+// CreateNvmfTransport creates an NVMf transport, which has to exist before
+// listeners of its type are added. SPDK reports a transport that already
+// exists as invalid parameters, so that error is checked against the existing
+// transports and reported as ErrNvmfTransportExists.
+func (p *NvmfServiceImpl) CreateNvmfTransport(ctx context.Context, params NvmfTransportParams) error {
+	if !containsFold(nvmfTransports, params.Trtype) {
+		return status.Errorf(codes.InvalidArgument, "invalid trtype %q, expected one of %v", params.Trtype, nvmfTransports)
+	}
+	var result NvmfCreateTransportResult
+	err := p.client.Call(ctx, "nvmf_create_transport", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && p.hasTransport(ctx, params.Trtype) {
+			return &sentinelError{sentinel: ErrNvmfTransportExists, err: err}
+		}
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create transport: %s", params.Trtype)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetNvmfTransports lists all NVMf transports
+func (p *NvmfServiceImpl) GetNvmfTransports(ctx context.Context) ([]NvmfTransport, error) {
+	var result []NvmfTransport
+	err := p.client.Call(ctx, "nvmf_get_transports", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// hasTransport reports whether a transport of the given type exists,
+// a failure to list them counts as not existing
+func (p *NvmfServiceImpl) hasTransport(ctx context.Context, trtype string) bool {
+	transports, err := p.GetNvmfTransports(ctx)
+	if err != nil {
+		return false
+	}
+	for _, transport := range transports {
+		if strings.EqualFold(transport.Trtype, trtype) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNvmfNamespace attaches a bdev as a namespace of the subsystem and returns
+// the NSID SPDK assigned. SPDK reports every failure to add a namespace the
+// same way, so a bdev that does not exist is looked up first and reported as
+// ErrBdevNotFound, and an NSID already in use is reported as ErrNvmfNamespaceExists.
+func (p *NvmfServiceImpl) AddNvmfNamespace(ctx context.Context, nqn string, params NvmfNsParams) (uint32, error) {
+	if params.BdevName == "" {
+		return 0, status.Error(codes.InvalidArgument, "missing bdev_name")
+	}
+	if _, err := NewBdevService(p.client).GetBdevs(ctx, params.BdevName); err != nil {
+		return 0, err
+	}
+	rpcParams := NvmfSubsystemAddNsParams{
+		Nqn:       nqn,
+		Namespace: params,
+	}
+	var result uint32
+	err := p.client.Call(ctx, "nvmf_subsystem_add_ns", &rpcParams, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && params.Nsid != 0 && p.hasNamespace(ctx, nqn, uint32(params.Nsid)) {
+			return 0, &sentinelError{sentinel: ErrNvmfNamespaceExists, err: err}
+		}
+		return 0, err
+	}
+	return result, nil
+}
+
+// RemoveNvmfNamespace detaches the namespace from the subsystem
+func (p *NvmfServiceImpl) RemoveNvmfNamespace(ctx context.Context, nqn string, nsid uint32) error {
+	params := NvmfSubsystemRemoveNsParams{
+		Nqn:  nqn,
+		Nsid: int(nsid),
+	}
+	var result NvmfSubsystemRemoveNsResult
+	err := p.client.Call(ctx, "nvmf_subsystem_remove_ns", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not remove NS %d from NQN: %s", nsid, nqn)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// hasNamespace reports whether the subsystem has a namespace with the given
+// NSID, a failure to list the subsystems counts as not having it
+func (p *NvmfServiceImpl) hasNamespace(ctx context.Context, nqn string, nsid uint32) bool {
+	subsystems, err := p.GetNvmfSubsystems(ctx)
+	if err != nil {
+		return false
+	}
+	for _, subsystem := range subsystems {
+		if subsystem.Nqn != nqn {
+			continue
+		}
+		for _, ns := range subsystem.Namespaces {
+			if ns.Nsid == int(nsid) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callListener validates the listen address before sending it to SPDK
+func (p *NvmfServiceImpl) callListener(ctx context.Context, method string, nqn string, addr NvmfListenAddress) error {
+	if err := validateListenAddress(addr); err != nil {
+		return err
+	}
+	params := NvmfSubsystemAddListenerParams{
+		Nqn:           nqn,
+		ListenAddress: addr,
+	}
+	var result NvmfSubsystemAddListenerResult
+	err := p.client.Call(ctx, method, &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not update listener %s:%s on NQN: %s", addr.Traddr, addr.Trsvcid, nqn)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// validateListenAddress rejects unknown transport types and address families
+func validateListenAddress(addr NvmfListenAddress) error {
+	if !containsFold(nvmfListenerTransports, addr.Trtype) {
+		return status.Errorf(codes.InvalidArgument, "invalid trtype %q, expected one of %v", addr.Trtype, nvmfListenerTransports)
+	}
+	if addr.Adrfam != "" && !containsFold(nvmfAddressFamilies, addr.Adrfam) {
+		return status.Errorf(codes.InvalidArgument, "invalid adrfam %q, expected one of %v", addr.Adrfam, nvmfAddressFamilies)
+	}
+	if addr.Traddr == "" {
+		return status.Error(codes.InvalidArgument, "missing traddr")
+	}
+	return nil
+}
+
+// containsFold reports whether value is in values, ignoring case as SPDK does
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNvmfService_CreateNvmfSubsystem(t *testing.T) {
+	// SPDK reports a duplicate NQN like any other failure to create a subsystem
+	createFailed := &RPCError{Code: InternalErrorCode, Message: "Unable to create subsystem nqn.2016-06.io.spdk:cnode1"}
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"created": {
+			NewMockJSONRPC().On("nvmf_create_subsystem", true),
+			nil,
+		},
+		"already exists": {
+			NewMockJSONRPC().OnError("nvmf_create_subsystem", createFailed).
+				On("nvmf_get_subsystems", `[{"nqn":"nqn.2016-06.io.spdk:cnode1"}]`),
+			ErrNvmfSubsystemExists,
+		},
+		"other failure": {
+			NewMockJSONRPC().OnError("nvmf_create_subsystem", createFailed).
+				On("nvmf_get_subsystems", `[{"nqn":"nqn.2016-06.io.spdk:cnode2"}]`),
+			createFailed,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("nvmf_create_subsystem", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := NvmfCreateSubsystemParams{Nqn: "nqn.2016-06.io.spdk:cnode1", SerialNumber: "SPDK1", MaxNamespaces: 8}
+			err := NewNvmfService(tt.mock).CreateNvmfSubsystem(context.Background(), params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if exists := errors.Is(err, ErrNvmfSubsystemExists); exists != (tt.wantErr == ErrNvmfSubsystemExists) {
+				t.Error("exists: expected", !exists, "received", exists)
+			}
+		})
+	}
+}
+
+func TestNvmfService_GetNvmfSubsystems(t *testing.T) {
+	mock := NewMockJSONRPC().On("nvmf_get_subsystems", `[{"nqn":"nqn.2016-06.io.spdk:cnode1","subtype":"NVMe",
+		"listen_addresses":[{"trtype":"TCP","adrfam":"IPv4","traddr":"127.0.0.1","trsvcid":"4420"}],
+		"allow_any_host":false,"hosts":[{"nqn":"nqn.2014-08.org.nvmexpress:host1"}],
+		"namespaces":[{"nsid":1,"bdev_name":"Malloc0","name":"Malloc0"}]}]`)
+	got, err := NewNvmfService(mock).GetNvmfSubsystems(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []NvmfSubsystem{{
+		Nqn:             "nqn.2016-06.io.spdk:cnode1",
+		Subtype:         "NVMe",
+		ListenAddresses: []NvmfListenAddress{{Trtype: "TCP", Adrfam: "IPv4", Traddr: "127.0.0.1", Trsvcid: "4420"}},
+		Hosts:           []NvmfHost{{Nqn: "nqn.2014-08.org.nvmexpress:host1"}},
+		Namespaces:      []NvmfNamespace{{Nsid: 1, BdevName: "Malloc0", Name: "Malloc0"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestNvmfService_AddNvmfListener(t *testing.T) {
+	tests := map[string]struct {
+		addr      NvmfListenAddress
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		"valid tcp": {
+			NvmfListenAddress{Trtype: "TCP", Adrfam: "IPv4", Traddr: "127.0.0.1", Trsvcid: "4420"},
+			codes.OK,
+			1,
+		},
+		"lower case pcie without adrfam": {
+			NvmfListenAddress{Trtype: "pcie", Traddr: "0000:01:00.0"},
+			codes.OK,
+			1,
+		},
+		"invalid trtype": {
+			NvmfListenAddress{Trtype: "TPC", Adrfam: "IPv4", Traddr: "127.0.0.1"},
+			codes.InvalidArgument,
+			0,
+		},
+		"invalid adrfam": {
+			NvmfListenAddress{Trtype: "TCP", Adrfam: "IPv5", Traddr: "127.0.0.1"},
+			codes.InvalidArgument,
+			0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMockJSONRPC().On("nvmf_subsystem_add_listener", true)
+			err := NewNvmfService(mock).AddNvmfListener(context.Background(), "nqn.2016-06.io.spdk:cnode1", tt.addr)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", code)
+			}
+			if calls := len(mock.Calls()); calls != tt.wantCalls {
+				t.Error("calls: expected", tt.wantCalls, "received", calls)
+			}
+		})
+	}
+}
+
+func TestNvmfService_AddNvmfNamespace(t *testing.T) {
+	addFailed := &RPCError{Code: InternalErrorCode, Message: "Invalid parameters"}
+	subsystems := `[{"nqn":"nqn.2016-06.io.spdk:cnode1","namespaces":[{"nsid":1,"bdev_name":"Malloc0"}]}]`
+	tests := map[string]struct {
+		params      NvmfNsParams
+		mock        *MockJSONRPC
+		want        uint32
+		wantErr     error
+		wantMethods []string
+	}{
+		"assigned nsid": {
+			NvmfNsParams{BdevName: "Malloc1"},
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc1"}]`).On("nvmf_subsystem_add_ns", 2),
+			2,
+			nil,
+			[]string{"bdev_get_bdevs", "nvmf_subsystem_add_ns"},
+		},
+		"bdev not found": {
+			NvmfNsParams{BdevName: "Missing"},
+			NewMockJSONRPC().OnError("bdev_get_bdevs", &RPCError{Code: ENODEVCode, Message: "No such device"}),
+			0,
+			ErrBdevNotFound,
+			[]string{"bdev_get_bdevs"},
+		},
+		"nsid in use": {
+			NvmfNsParams{BdevName: "Malloc1", Nsid: 1},
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc1"}]`).OnError("nvmf_subsystem_add_ns", addFailed).On("nvmf_get_subsystems", subsystems),
+			0,
+			ErrNvmfNamespaceExists,
+			[]string{"bdev_get_bdevs", "nvmf_subsystem_add_ns", "nvmf_get_subsystems"},
+		},
+		"other failure": {
+			NvmfNsParams{BdevName: "Malloc1", Nsid: 3},
+			NewMockJSONRPC().On("bdev_get_bdevs", `[{"name":"Malloc1"}]`).OnError("nvmf_subsystem_add_ns", addFailed).On("nvmf_get_subsystems", subsystems),
+			0,
+			addFailed,
+			[]string{"bdev_get_bdevs", "nvmf_subsystem_add_ns", "nvmf_get_subsystems"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewNvmfService(tt.mock).AddNvmfNamespace(context.Background(), "nqn.2016-06.io.spdk:cnode1", tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantErr == addFailed && errors.Is(err, ErrNvmfNamespaceExists) {
+				t.Error("error: unexpected sentinel", err)
+			}
+			if got != tt.want {
+				t.Error("nsid: expected", tt.want, "received", got)
+			}
+			calls := tt.mock.Calls()
+			var methods []string
+			for _, call := range calls {
+				methods = append(methods, call.Method)
+			}
+			if !reflect.DeepEqual(methods, tt.wantMethods) {
+				t.Error("methods: expected", tt.wantMethods, "received", methods)
+			}
+			if len(calls) > 1 {
+				want := &NvmfSubsystemAddNsParams{Nqn: "nqn.2016-06.io.spdk:cnode1", Namespace: tt.params}
+				if !reflect.DeepEqual(calls[1].Args, want) {
+					t.Error("args: expected", want, "received", calls[1].Args)
+				}
+			}
+		})
+	}
+}
+
+func TestNvmfService_AddNvmfNamespaceMissingBdev(t *testing.T) {
+	mock := NewMockJSONRPC()
+	_, err := NewNvmfService(mock).AddNvmfNamespace(context.Background(), "nqn.2016-06.io.spdk:cnode1", NvmfNsParams{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("code: expected", codes.InvalidArgument, "received", err)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Error("calls: expected none received", calls)
+	}
+}
+
+func TestNvmfService_RemoveNvmfNamespace(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"removed": {
+			NewMockJSONRPC().On("nvmf_subsystem_remove_ns", true),
+			nil,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("nvmf_subsystem_remove_ns", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewNvmfService(tt.mock).RemoveNvmfNamespace(context.Background(), "nqn.2016-06.io.spdk:cnode1", 2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			want := &NvmfSubsystemRemoveNsParams{Nqn: "nqn.2016-06.io.spdk:cnode1", Nsid: 2}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+				t.Error("args: expected", want, "received", args)
+			}
+		})
+	}
+}
+
+func TestNvmfService_CreateNvmfTransport(t *testing.T) {
+	invalid := &RPCError{Code: InvalidParamsCode, Message: "Invalid parameters"}
+	tests := map[string]struct {
+		trtype   string
+		mock     *MockJSONRPC
+		wantErr  error
+		wantCode codes.Code
+	}{
+		"created": {
+			"TCP",
+			NewMockJSONRPC().On("nvmf_create_transport", true),
+			nil,
+			codes.OK,
+		},
+		"already exists": {
+			"tcp",
+			NewMockJSONRPC().OnError("nvmf_create_transport", invalid).On("nvmf_get_transports", `[{"trtype":"TCP"}]`),
+			ErrNvmfTransportExists,
+			codes.InvalidArgument,
+		},
+		"invalid parameters": {
+			"RDMA",
+			NewMockJSONRPC().OnError("nvmf_create_transport", invalid).On("nvmf_get_transports", `[{"trtype":"TCP"}]`),
+			invalid,
+			codes.InvalidArgument,
+		},
+		"unknown transport": {
+			"PCIE",
+			NewMockJSONRPC(),
+			nil,
+			codes.InvalidArgument,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := NvmfTransportParams{Trtype: tt.trtype, MaxQueueDepth: 128, IoUnitSize: 8192}
+			err := NewNvmfService(tt.mock).CreateNvmfTransport(context.Background(), params)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if tt.wantErr == invalid && errors.Is(err, ErrNvmfTransportExists) {
+				t.Error("error: unexpected sentinel", err)
+			}
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", code)
+			}
+			if calls := tt.mock.Calls(); len(calls) != 0 && !reflect.DeepEqual(calls[0].Args, &params) {
+				t.Error("args: expected", &params, "received", calls[0].Args)
+			}
+		})
+	}
+}
+
+func TestNvmfService_GetNvmfTransports(t *testing.T) {
+	mock := NewMockJSONRPC().On("nvmf_get_transports", `[{"trtype":"TCP","max_queue_depth":128,"max_io_qpairs_per_ctrlr":127,`+
+		`"in_capsule_data_size":4096,"max_io_size":131072,"io_unit_size":131072,"max_aq_depth":128,"num_shared_buffers":511,"buf_cache_size":32}]`)
+	got, err := NewNvmfService(mock).GetNvmfTransports(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []NvmfTransport{{
+		Trtype: "TCP", MaxQueueDepth: 128, MaxIoQpairsPerCtrlr: 127, InCapsuleDataSize: 4096,
+		MaxIoSize: 131072, IoUnitSize: 131072, MaxAqDepth: 128, NumSharedBuffers: 511, BufCacheSize: 32,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
@@ -0,0 +1,334 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/opiproject/gospdk/spdk"
+
+// DefaultTimeout bounds a single SPDK call when no other timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// Option configures optional behavior of a Client
+type Option func(*Client)
+
+// DialFunc connects to the address on the named network, with the same
+// semantics as net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithTimeout sets the read/write deadline applied to every SPDK call,
+// zero disables the deadline
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithCallTimeout is WithTimeout under the name that pairs with
+// WithDialTimeout, it bounds the write and read once connected
+func WithCallTimeout(timeout time.Duration) Option {
+	return WithTimeout(timeout)
+}
+
+// WithDialTimeout bounds connecting to SPDK, separately from the call
+// timeout which only starts once connected. A dial that runs out of time
+// fails as Unavailable, so it is retried like any other unreachable SPDK.
+// The context deadline still applies when it is earlier. Zero, the default,
+// leaves dialing bounded by the context only.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.dialTimeout = timeout
+	}
+}
+
+// WithPersistentConnection keeps a single long-lived connection to SPDK and
+// reuses it for every call instead of dialing a new one per call. A write that
+// fails because SPDK closed the connection is retried once on a new one.
+func WithPersistentConnection() Option {
+	return func(c *Client) {
+		c.persistent = true
+	}
+}
+
+// WithMultiplexing keeps a single long-lived connection to SPDK and allows
+// many calls to be in flight on it at once, matching responses by id
+func WithMultiplexing() Option {
+	return func(c *Client) {
+		c.persistent = true
+		c.multiplex = true
+	}
+}
+
+// WithLogger routes the client logging through the given logger,
+// use NopLogger to silence it
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRequestLogging turns the log line of every request sent to SPDK on or
+// off, it is on by default. Request hooks are called either way.
+func WithRequestLogging(enabled bool) Option {
+	return func(c *Client) {
+		c.noRequestLog = !enabled
+	}
+}
+
+// WithResponseLogging turns the log line of every response received from SPDK
+// on or off, it is on by default. Responses can be large, e.g. full bdev
+// listings, so this keeps the request lines while dropping the bulk of the
+// log volume. Response hooks are called either way.
+func WithResponseLogging(enabled bool) Option {
+	return func(c *Client) {
+		c.noResponseLog = !enabled
+	}
+}
+
+// WithRedactedFields masks the values of the given JSON keys, at any nesting
+// level, in the logged copies of requests and responses. The bytes sent to
+// SPDK are never modified.
+func WithRedactedFields(fields ...string) Option {
+	return func(c *Client) {
+		if c.redacted == nil {
+			c.redacted = make(map[string]struct{}, len(fields))
+		}
+		for _, field := range fields {
+			c.redacted[field] = struct{}{}
+		}
+	}
+}
+
+// WithLogRedactor passes the logged copy of every request and response through
+// redactor, after WithRedactedFields, to mask secrets that are not found by
+// key, e.g. credentials inside a URI. It applies to the log lines as well as
+// to what the request and response hooks and traces see, the bytes sent to
+// and decoded from SPDK are never modified. redactor may modify its argument.
+func WithLogRedactor(redactor func([]byte) []byte) Option {
+	return func(c *Client) {
+		c.logRedactor = redactor
+	}
+}
+
+// WithRetry makes up to maxAttempts attempts, with exponential backoff and
+// jitter starting at baseDelay and capped at MaxRetryDelay, when SPDK cannot
+// be reached. Application errors returned by SPDK are deterministic and never
+// retried.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retryAttempts = maxAttempts
+		c.retryDelay = baseDelay
+	}
+}
+
+// WithDialer replaces the default net.Dialer.DialContext used to connect
+// to SPDK, e.g. to go through a proxy or an in-process net.Pipe
+func WithDialer(dialer DialFunc) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithSocketBuffers sets the kernel receive and send buffer sizes, SO_RCVBUF
+// and SO_SNDBUF, of every TCP connection to SPDK, which can speed up large
+// config loads and responses across hosts. Zero keeps the system default and
+// unix sockets are left untouched.
+func WithSocketBuffers(read, write int) Option {
+	return func(c *Client) {
+		c.readBuffer = read
+		c.writeBuffer = write
+	}
+}
+
+// WithTLS secures tcp connections to SPDK with the given configuration,
+// including client certificates for mutual TLS. Unix sockets ignore it.
+func WithTLS(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithMaxResponseBytes caps the size of a single SPDK response, calls fail with
+// ErrResponseTooLarge beyond it. Zero or negative disables the cap.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithMetrics reports the method, duration and error of every call to hook,
+// the duration covers retries, the exchange with SPDK and decoding the response
+func WithMetrics(hook MetricsHook) Option {
+	return func(c *Client) {
+		c.metrics = hook
+	}
+}
+
+// WithTracerProvider creates the spans of every call, named spdk.<method>,
+// from the given provider instead of the global one
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithJSONRPCVersion sets the jsonrpc member sent with every request, an empty
+// version omits the member altogether for endpoints that reject it
+func WithJSONRPCVersion(version string) Option {
+	return func(c *Client) {
+		c.rpcVersion = version
+	}
+}
+
+// WithIDGenerator replaces the per-client counter used for request ids, e.g.
+// to keep ids unique across restarts in shared SPDK logs. The generator is
+// called concurrently and must never return zero, which JSON-RPC reserves
+// for notifications here, nor repeat an id that is still in flight.
+func WithIDGenerator(generator func() uint64) Option {
+	return func(c *Client) {
+		c.generateID = generator
+	}
+}
+
+// WithMaxConcurrency allows at most n calls to be exchanged with SPDK at once,
+// the others wait for a free slot or for their context to be done. The default,
+// zero or negative n, is unlimited.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		c.inflight = nil
+		if n > 0 {
+			c.inflight = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithInterceptors wraps every Call in the given interceptors, the first one
+// being the outermost. Repeating the option appends to the chain.
+func WithInterceptors(interceptors ...CallInterceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithIdleTimeout closes the persistent connection once it has not been used
+// for the given duration, the next call dials a new one. Zero keeps it open
+// indefinitely. It only applies together with WithPersistentConnection or
+// WithMultiplexing.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.idleTimeout = timeout
+	}
+}
+
+// WithKeepAlive pings SPDK on the persistent connection whenever it has not
+// been used for interval, so middleboxes do not drop it while idle and a
+// broken connection is redialed before the next call needs it. Pings count as
+// use, so an interval below the idle timeout keeps WithIdleTimeout from
+// closing the connection. Pings skip the interceptors, metrics, spans, hooks
+// and logs of calls. It only applies together with WithPersistentConnection
+// or WithMultiplexing.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = interval
+	}
+}
+
+// WithRequestHook calls hook with the params of every request, redacted as
+// configured by WithRedactedFields, instead of logging the raw request
+func WithRequestHook(hook RequestHook) Option {
+	return func(c *Client) {
+		c.onRequest = hook
+	}
+}
+
+// WithResponseHook calls hook with the result or error of every response
+// received, redacted as configured by WithRedactedFields, instead of logging
+// the raw response. The id is the one of the request that was sent.
+func WithResponseHook(hook ResponseHook) Option {
+	return func(c *Client) {
+		c.onResponse = hook
+	}
+}
+
+// WithWireTap calls tap with the exact bytes of every request written to and
+// every response read from SPDK, e.g. to record traffic for replay. Unlike
+// logging and the hooks nothing is redacted. Responses on a dedicated
+// connection are passed whole, on persistent and multiplexed connections in
+// the chunks they are read in, which may split or join responses. A request
+// resent after SPDK dropped an idle connection is passed again, including the
+// bytes that reached the dropped one. The tap must not modify b and has to
+// copy it to keep it past the call.
+func WithWireTap(tap WireTap) Option {
+	return func(c *Client) {
+		c.wireTap = tap
+	}
+}
+
+// WithoutHalfClose keeps the write side of the connection open after sending
+// a request and reads a single JSON value as the response instead of reading
+// until SPDK closes the connection. Use it with intermediaries that treat a
+// half-close as a full close. Persistent connections never half-close.
+func WithoutHalfClose() Option {
+	return func(c *Client) {
+		c.noHalfClose = true
+	}
+}
+
+// WithStreamedRequests encodes the requests of the given methods, or of all
+// methods when none are given, straight onto the connection with a
+// json.Encoder instead of marshalling them into a buffer first, saving a copy
+// of multi-megabyte payloads such as load_config. The request body of a
+// streamed call is not logged, redacted or passed to the request hook, and
+// calls on a persistent or multiplexed connection are always buffered.
+func WithStreamedRequests(methods ...string) Option {
+	return func(c *Client) {
+		c.streamRequests = true
+		if len(methods) == 0 {
+			c.streamMethods = nil
+			return
+		}
+		c.streamMethods = make(map[string]struct{}, len(methods))
+		for _, method := range methods {
+			c.streamMethods[method] = struct{}{}
+		}
+	}
+}
+
+// peerCred holds the credentials the process serving the unix socket must
+// run with, a negative id is not checked
+type peerCred struct {
+	uid int
+	gid int
+}
+
+// WithPeerCredCheck refuses to talk to SPDK over a unix socket unless the
+// process that accepted the connection runs with the given uid and gid, as
+// reported by SO_PEERCRED. Pass -1 to leave either unchecked. It is a no-op
+// for TCP, for connections from a custom dialer that are not *net.UnixConn,
+// and on platforms other than Linux.
+func WithPeerCredCheck(allowedUID, allowedGID int) Option {
+	return func(c *Client) {
+		c.peerCred = &peerCred{uid: allowedUID, gid: allowedGID}
+	}
+}
+
+// WithStrictDecoding fails calls whose result has fields the result value
+// has no place for, instead of ignoring them, to catch SPDK schema changes
+// in tests. The result check applies to Call and Batch, not to RawCall, and
+// types with their own UnmarshalJSON keep deciding for themselves. Responses
+// without a jsonrpc member are rejected as well, by RawCall too.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
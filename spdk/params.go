@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PositionalParams are sent as a JSON array, by position, instead of the
+// by-name object SPDK methods take. The JSON-RPC 2.0 spec allows either, so
+// use it to be explicit when calling a peer that expects positional params.
+type PositionalParams []interface{}
+
+// CallPositional is Call with by-position params, no args omits the params
+// member altogether
+func (r *Client) CallPositional(ctx context.Context, method string, args []interface{}, result interface{}) error {
+	if len(args) == 0 {
+		return r.Call(ctx, method, nil, result)
+	}
+	return r.Call(ctx, method, PositionalParams(args), result)
+}
+
+// validateParams rejects args that cannot encode to the structured value
+// JSON-RPC 2.0 requires for params: an object or an array. Types with their
+// own JSON encoding, and nil pointers, are left to the encoder.
+func validateParams(method string, args interface{}) error {
+	if args == nil {
+		return nil
+	}
+	if _, ok := args.(json.Marshaler); ok {
+		return nil
+	}
+	v := reflect.ValueOf(args)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return nil
+	}
+	return status.Errorf(codes.InvalidArgument, "%s: params must be an object or an array, not %T", method, args)
+}
+
+// omitNilParams returns nil for nil pointers, maps and slices, so that the
+// params member is left out of the request rather than sent as null, as
+// rpc.py does for methods without params
+func omitNilParams(args interface{}) interface{} {
+	if args == nil {
+		return nil
+	}
+	v := reflect.ValueOf(args)
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+	case reflect.Map, reflect.Slice:
+		if _, ok := args.(json.Marshaler); !ok && v.IsNil() {
+			return nil
+		}
+	}
+	return args
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_CallPositional(t *testing.T) {
+	tests := map[string]struct {
+		args []interface{}
+		want string
+	}{
+		"positional": {[]interface{}{"Malloc0", 42}, `["Malloc0",42]`},
+		"no args":    {nil, ""},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			sent := make(chan json.RawMessage, 1)
+			serve(ln, func(req RPCRequest) string {
+				raw, _ := json.Marshal(req.Params)
+				if req.Params == nil {
+					raw = nil
+				}
+				sent <- raw
+				return `{"jsonrpc":"2.0","id":1,"result":true}`
+			})
+
+			var result bool
+			if err := client.CallPositional(context.Background(), "echo", tt.args, &result); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if got := string(<-sent); got != tt.want {
+				t.Error("params: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestSpdk_OmitNilParams(t *testing.T) {
+	var nilParams *BdevGetBdevsParams
+	var nilMap map[string]interface{}
+	tests := map[string]struct {
+		args interface{}
+		want bool
+	}{
+		"nil":         {nil, false},
+		"nil pointer": {nilParams, false},
+		"nil map":     {nilMap, false},
+		"empty map":   {map[string]interface{}{}, true},
+		"params":      {&BdevGetBdevsParams{Name: "Malloc0"}, true},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			sent := make(chan string, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				var raw json.RawMessage
+				if err := json.NewDecoder(conn).Decode(&raw); err != nil {
+					return
+				}
+				sent <- string(raw)
+				var req RPCRequest
+				_ = json.Unmarshal(raw, &req)
+				fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+			}()
+
+			var result bool
+			if err := client.Call(context.Background(), "spdk_get_version", tt.args, &result); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			wire := <-sent
+			if got := strings.Contains(wire, `"params"`); got != tt.want {
+				t.Error("params: expected", tt.want, "received", wire)
+			}
+		})
+	}
+}
+
+func TestSpdk_ValidateParams(t *testing.T) {
+	var nilParams *BdevGetBdevsParams
+	tests := map[string]struct {
+		args    interface{}
+		wantErr bool
+	}{
+		"nil":             {nil, false},
+		"struct":          {BdevGetBdevsParams{Name: "Malloc0"}, false},
+		"struct pointer":  {&BdevGetBdevsParams{Name: "Malloc0"}, false},
+		"nil pointer":     {nilParams, false},
+		"map":             {map[string]string{"name": "Malloc0"}, false},
+		"slice":           {[]string{"Malloc0"}, false},
+		"positional":      {PositionalParams{"Malloc0", 1}, false},
+		"raw message":     {json.RawMessage(`{"name":"Malloc0"}`), false},
+		"string":          {"Malloc0", true},
+		"number":          {42, true},
+		"boolean pointer": {new(bool), true},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateParams("bdev_get_bdevs", tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if err != nil && status.Code(err) != codes.InvalidArgument {
+				t.Error("code: expected", codes.InvalidArgument, "received", status.Code(err))
+			}
+		})
+	}
+}
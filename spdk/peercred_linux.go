@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+//go:build linux
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"net"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkPeerCred compares the credentials of the process on the other end of
+// a unix socket, as reported by SO_PEERCRED, with the allowed ones. Other
+// connection types are not checked.
+func checkPeerCred(conn net.Conn, cred *peerCred) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ucred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return err
+	}
+	if credErr != nil {
+		return status.Errorf(codes.PermissionDenied, "could not get SPDK peer credentials: %v", credErr)
+	}
+	if cred.uid >= 0 && int(ucred.Uid) != cred.uid {
+		return status.Errorf(codes.PermissionDenied, "SPDK peer runs as uid %d, expected %d", ucred.Uid, cred.uid)
+	}
+	if cred.gid >= 0 && int(ucred.Gid) != cred.gid {
+		return status.Errorf(codes.PermissionDenied, "SPDK peer runs as gid %d, expected %d", ucred.Gid, cred.gid)
+	}
+	return nil
+}
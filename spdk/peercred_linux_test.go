@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+//go:build linux
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_WithPeerCredCheck(t *testing.T) {
+	uid, gid := os.Getuid(), os.Getgid()
+	tests := map[string]struct {
+		uid      int
+		gid      int
+		wantCode codes.Code
+	}{
+		"matching":     {uid, gid, codes.OK},
+		"unchecked":    {-1, -1, codes.OK},
+		"uid mismatch": {uid + 1, -1, codes.PermissionDenied},
+		"gid mismatch": {-1, gid + 1, codes.PermissionDenied},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}), WithPeerCredCheck(tt.uid, tt.gid))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			serve(ln, func(req RPCRequest) string {
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)
+			})
+
+			var result bool
+			err := client.Call(context.Background(), "bdev_wait_for_examine", nil, &result)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+		})
+	}
+}
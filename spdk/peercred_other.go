@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+//go:build !linux
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"net"
+)
+
+// checkPeerCred does nothing where SO_PEERCRED is not available
+func checkPeerCred(net.Conn, *peerCred) error {
+	return nil
+}
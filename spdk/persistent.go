@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// muxConn is a persistent connection shared by concurrent calls
+type muxConn struct {
+	conn    net.Conn
+	pending map[uint64]chan RPCResponse
+	err     error
+}
+
+// exchangePersistent sends the request over the long-lived connection and
+// decodes exactly one response object from it. SPDK keeps the socket open,
+// so responses are delimited by JSON object boundaries rather than by EOF.
+func (r *Client) exchangePersistent(ctx context.Context, buf []byte) (RPCResponse, error) {
+	var response RPCResponse
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil && r.idleLocked() {
+		r.logger.Printf("Closing SPDK connection idle for more than %v", r.idleTimeout)
+		_ = r.closeLocked()
+	}
+	reused := r.conn != nil
+	if err := r.writePersistentLocked(ctx, buf); err != nil {
+		_ = r.closeLocked()
+		// SPDK dropped the connection while it was idle, try once more on a fresh one
+		if !reused || !isBrokenPipe(err) {
+			return response, transportError(ctx, err)
+		}
+		r.logger.Printf("Redialing SPDK after: %v", err)
+		if err := r.writePersistentLocked(ctx, buf); err != nil {
+			_ = r.closeLocked()
+			return response, transportError(ctx, err)
+		}
+	}
+	resetLimit(r.limiter)
+	stop := watchCancel(ctx, r.conn)
+	err := r.decoder.Decode(&response)
+	stop()
+	if err != nil {
+		// the stream is out of sync now, next call dials a fresh connection
+		_ = r.closeLocked()
+		if err == io.EOF {
+			return response, ErrEmptyResponse
+		}
+		return response, transportError(ctx, err)
+	}
+	r.lastUsed = time.Now()
+	return response, nil
+}
+
+// writePersistentLocked dials the persistent connection if needed and writes
+// the request to it
+func (r *Client) writePersistentLocked(ctx context.Context, buf []byte) error {
+	if err := r.connectPersistentLocked(ctx); err != nil {
+		return err
+	}
+	// zero deadline clears the one left over from a previous call
+	deadline, _ := r.deadline(ctx)
+	if err := r.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	n, err := r.conn.Write(buf)
+	r.tapSent(buf, n)
+	return err
+}
+
+// connectPersistentLocked dials the persistent connection unless it is open
+func (r *Client) connectPersistentLocked(ctx context.Context) error {
+	if r.conn != nil {
+		return nil
+	}
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	r.limiter = newLimitReader(r.tapConn(conn), r.maxResponseBytes)
+	r.decoder = json.NewDecoder(r.limiter)
+	r.lastUsed = time.Now()
+	r.startKeepAliveLocked()
+	return nil
+}
+
+// Connect dials SPDK right away, so that an unreachable or misconfigured
+// socket is reported at startup rather than by the first call. With
+// WithPersistentConnection or WithMultiplexing the connection is kept for
+// the calls that follow, otherwise it is closed again. Connecting is
+// optional, calls dial on demand either way. Follow up with Ping to also
+// check that SPDK answers.
+func (r *Client) Connect(ctx context.Context) error {
+	if !r.persistent {
+		conn, err := r.dial(ctx)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.multiplex {
+		_, err := r.muxLocked(ctx)
+		if err == nil {
+			r.lastUsed = time.Now()
+		}
+		return err
+	}
+	return r.connectPersistentLocked(ctx)
+}
+
+// idleLocked reports whether the persistent connection went unused for longer
+// than the configured idle timeout
+func (r *Client) idleLocked() bool {
+	return r.idleTimeout > 0 && time.Since(r.lastUsed) > r.idleTimeout
+}
+
+// isBrokenPipe reports whether a write failed because the peer closed the connection
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// Close releases the persistent connection, if any, and stops the keep-alive
+// pings until the next call connects again. It returns once a ping in flight
+// has finished.
+func (r *Client) Close() error {
+	r.mu.Lock()
+	stop, done := r.stopPings, r.pingsDone
+	r.mu.Unlock()
+	if stop != nil {
+		// the pings take r.mu themselves, so wait for them without holding it
+		stop()
+		<-done
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pingsDone == done {
+		r.stopPings = nil
+		r.pingsDone = nil
+	}
+	return r.closeLocked()
+}
+
+func (r *Client) closeLocked() error {
+	var err error
+	if r.mux != nil {
+		// readLoop notices and fails the pending calls
+		err = r.mux.conn.Close()
+		r.mux = nil
+	}
+	if r.conn != nil {
+		err = r.conn.Close()
+		r.conn = nil
+		r.limiter = nil
+		r.decoder = nil
+	}
+	return err
+}
+
+// exchangeMultiplexed registers the request id, sends the request over the
+// shared connection and waits for readLoop to dispatch the matching response
+func (r *Client) exchangeMultiplexed(ctx context.Context, id uint64, buf []byte) (RPCResponse, error) {
+	var response RPCResponse
+	ch := make(chan RPCResponse, 1)
+
+	r.mu.Lock()
+	m, reused, err := r.sendMultiplexedLocked(ctx, id, buf, ch)
+	if err != nil && reused && isBrokenPipe(err) {
+		// SPDK dropped the connection while it was idle, try once more on a fresh one
+		r.logger.Printf("Redialing SPDK after: %v", err)
+		m, _, err = r.sendMultiplexedLocked(ctx, id, buf, ch)
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return response, transportError(ctx, err)
+	}
+
+	deadline, ok := r.deadline(ctx)
+	var timeout <-chan time.Time
+	if ok {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case response, ok = <-ch:
+		if !ok {
+			r.mu.Lock()
+			err := m.err
+			r.mu.Unlock()
+			if err == io.EOF {
+				return response, ErrEmptyResponse
+			}
+			if _, isStatus := status.FromError(err); err != nil && isStatus {
+				return response, err
+			}
+			// the request was sent and may have run, so this is no Unavailable to retry
+			return response, status.Errorf(codes.Aborted, "connection to SPDK closed before response was received: %v", err)
+		}
+		return response, nil
+	case <-ctx.Done():
+		r.forget(m, id)
+		return response, status.FromContextError(ctx.Err()).Err()
+	case <-timeout:
+		r.forget(m, id)
+		return response, status.Error(codes.DeadlineExceeded, "timed out waiting for SPDK response")
+	}
+}
+
+// sendMultiplexedLocked registers ch for the response to id and writes the
+// request to the shared connection, reporting whether that connection was
+// already open before this call
+func (r *Client) sendMultiplexedLocked(ctx context.Context, id uint64, buf []byte, ch chan RPCResponse) (*muxConn, bool, error) {
+	if r.mux != nil && len(r.mux.pending) == 0 && r.idleLocked() {
+		r.logger.Printf("Closing SPDK connection idle for more than %v", r.idleTimeout)
+		_ = r.mux.conn.Close()
+		r.mux = nil
+	}
+	reused := r.mux != nil
+	m, err := r.muxLocked(ctx)
+	if err != nil {
+		return nil, reused, err
+	}
+	m.pending[id] = ch
+	deadline, _ := r.deadline(ctx)
+	if err := m.conn.SetWriteDeadline(deadline); err != nil {
+		delete(m.pending, id)
+		return nil, reused, err
+	}
+	n, err := m.conn.Write(buf)
+	r.tapSent(buf, n)
+	if err != nil {
+		// a partial write corrupts the stream for everyone
+		delete(m.pending, id)
+		_ = m.conn.Close()
+		r.mux = nil
+		return nil, reused, err
+	}
+	r.lastUsed = time.Now()
+	return m, reused, nil
+}
+
+// muxLocked returns the shared connection, dialing it first if needed
+func (r *Client) muxLocked(ctx context.Context) (*muxConn, error) {
+	if r.mux == nil {
+		conn, err := r.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.mux = &muxConn{conn: conn, pending: make(map[uint64]chan RPCResponse)}
+		go r.readLoop(r.mux)
+		r.startKeepAliveLocked()
+	}
+	return r.mux, nil
+}
+
+// forget drops a pending call that is no longer waiting for its response
+func (r *Client) forget(m *muxConn, id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(m.pending, id)
+}
+
+// readLoop decodes responses from the shared connection and hands each one
+// to the call waiting on its id, until the connection fails or is closed
+func (r *Client) readLoop(m *muxConn) {
+	limiter := newLimitReader(r.tapConn(m.conn), r.maxResponseBytes)
+	decoder := json.NewDecoder(limiter)
+	var err error
+	for {
+		var response RPCResponse
+		resetLimit(limiter)
+		if err = decoder.Decode(&response); err != nil {
+			break
+		}
+		r.mu.Lock()
+		ch, ok := m.pending[response.ID]
+		delete(m.pending, response.ID)
+		r.lastUsed = time.Now()
+		r.mu.Unlock()
+		if !ok {
+			r.logger.Printf("Dropping SPDK response with unknown id: %d", response.ID)
+			continue
+		}
+		ch <- response
+	}
+	_ = m.conn.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mux == m {
+		r.mux = nil
+	}
+	m.err = err
+	for id, ch := range m.pending {
+		close(ch)
+		delete(m.pending, id)
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPoolCooldown is how long Pool skips an endpoint after it could not be reached
+const DefaultPoolCooldown = 10 * time.Second
+
+// Pool is a JSONRPC that spreads calls round-robin over several SPDK
+// instances. It is meant for read-only queries whose answer does not depend
+// on which instance serves them. Unreachable instances are skipped until
+// their cool-down has passed.
+type Pool struct {
+	// first, as only the first word of an allocated struct is 8-byte aligned
+	// for the 64-bit atomics on 32-bit platforms
+	cooldown int64
+	// each endpoint is allocated on its own so that failedUntil is 8-byte
+	// aligned for the 64-bit atomics on 32-bit platforms
+	endpoints []*poolEndpoint
+	next      uint32
+}
+
+// poolEndpoint is a pooled client and the time, in unix nanoseconds,
+// until which it is skipped
+type poolEndpoint struct {
+	failedUntil int64
+	client      *Client
+}
+
+// build time check that struct implements interface
+var _ JSONRPC = (*Pool)(nil)
+
+// NewPool creates a client for every socket, all sharing the same options
+func NewPool(sockets []string, opts ...Option) (*Pool, error) {
+	if len(sockets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one socket is required")
+	}
+	endpoints := make([]*poolEndpoint, len(sockets))
+	for i, socket := range sockets {
+		client, err := NewClientE(socket, opts...)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[i] = &poolEndpoint{client: client}
+	}
+	return &Pool{endpoints: endpoints, cooldown: int64(DefaultPoolCooldown)}, nil
+}
+
+// SetCooldown changes how long an unreachable endpoint is skipped
+func (p *Pool) SetCooldown(cooldown time.Duration) {
+	atomic.StoreInt64(&p.cooldown, int64(cooldown))
+}
+
+// GetID returns the number of requests sent through the pool
+func (p *Pool) GetID() uint64 {
+	var id uint64
+	for i := range p.endpoints {
+		id += atomic.LoadUint64(&p.endpoints[i].client.id)
+	}
+	return id
+}
+
+// GetVersion asks the next endpoint for its version
+func (p *Pool) GetVersion(ctx context.Context) (SpdkVersion, error) {
+	var ver SpdkVersion
+	if err := p.Call(ctx, "spdk_get_version", nil, &ver); err != nil {
+		return SpdkVersion{}, err
+	}
+	return ver, nil
+}
+
+// StartUnixListener starts a listener on the first endpoint, for tests
+func (p *Pool) StartUnixListener() net.Listener {
+	return p.endpoints[0].client.StartUnixListener()
+}
+
+// Call sends the request to the next healthy endpoint in turn, moving on to
+// the following ones while they are unreachable. When every endpoint is
+// cooling down the call is still attempted rather than failed outright.
+func (p *Pool) Call(ctx context.Context, method string, args, result interface{}) error {
+	n := uint32(len(p.endpoints))
+	start := atomic.AddUint32(&p.next, 1) - 1
+	now := time.Now().UnixNano()
+	var err error
+	tried := false
+	for pass := 0; pass < 2 && !tried; pass++ {
+		for i := uint32(0); i < n; i++ {
+			endpoint := p.endpoints[(start+i)%n]
+			// the first pass skips endpoints that are cooling down
+			if pass == 0 && atomic.LoadInt64(&endpoint.failedUntil) > now {
+				continue
+			}
+			tried = true
+			err = endpoint.client.Call(ctx, method, args, result)
+			if !isUnreachable(err) || ctx.Err() != nil {
+				atomic.StoreInt64(&endpoint.failedUntil, 0)
+				return err
+			}
+			endpoint.client.logger.Printf("Skipping %s for %v after: %v", endpoint.client.socket, time.Duration(atomic.LoadInt64(&p.cooldown)), err)
+			atomic.StoreInt64(&endpoint.failedUntil, time.Now().UnixNano()+atomic.LoadInt64(&p.cooldown))
+		}
+	}
+	return err
+}
+
+// Connect connects to every endpoint, those that cannot be reached start
+// cooling down as if a call had failed. It fails only when none is reachable.
+func (p *Pool) Connect(ctx context.Context) error {
+	var err error
+	reachable := false
+	for i := range p.endpoints {
+		endpoint := p.endpoints[i]
+		if connectErr := endpoint.client.Connect(ctx); connectErr != nil {
+			err = connectErr
+			atomic.StoreInt64(&endpoint.failedUntil, time.Now().UnixNano()+atomic.LoadInt64(&p.cooldown))
+			continue
+		}
+		atomic.StoreInt64(&endpoint.failedUntil, 0)
+		reachable = true
+	}
+	if reachable {
+		return nil
+	}
+	return err
+}
+
+// Close releases the persistent connections of every endpoint
+func (p *Pool) Close() error {
+	var first error
+	for i := range p.endpoints {
+		if err := p.endpoints[i].client.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_Pool(t *testing.T) {
+	dir := t.TempDir()
+	sockets := []string{filepath.Join(dir, "spdk0.sock"), filepath.Join(dir, "spdk1.sock")}
+	listen := func(i int) net.Listener {
+		ln, err := net.Listen("unix", sockets[i])
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		serve(ln, func(req RPCRequest) string {
+			return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"spdk%d"}`, req.ID, i)
+		})
+		return ln
+	}
+	ln0, ln1 := listen(0), listen(1)
+	defer ln0.Close()
+
+	pool, err := NewPool(sockets, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	pool.SetCooldown(100 * time.Millisecond)
+	calls := func(n int) []string {
+		served := make([]string, n)
+		for i := range served {
+			if err := pool.Call(context.Background(), "bdev_get_bdevs", nil, &served[i]); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+		}
+		return served
+	}
+
+	if served := calls(4); !reflect.DeepEqual(served, []string{"spdk0", "spdk1", "spdk0", "spdk1"}) {
+		t.Error("round robin: unexpected", served)
+	}
+
+	// a failing endpoint is skipped until its cool-down has passed
+	_ = ln1.Close()
+	if served := calls(4); !reflect.DeepEqual(served, []string{"spdk0", "spdk0", "spdk0", "spdk0"}) {
+		t.Error("skipping: unexpected", served)
+	}
+	ln1 = listen(1)
+	defer ln1.Close()
+	time.Sleep(150 * time.Millisecond)
+	if served := calls(2); !reflect.DeepEqual(served, []string{"spdk0", "spdk1"}) {
+		t.Error("reintegrated: unexpected", served)
+	}
+	if id := pool.GetID(); id != 11 {
+		t.Error("requests: expected 11 received", id)
+	}
+}
+
+func TestSpdk_PoolUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	pool, err := NewPool([]string{filepath.Join(dir, "spdk0.sock"), filepath.Join(dir, "spdk1.sock")}, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := pool.Call(context.Background(), "bdev_get_bdevs", nil, nil); status.Code(err) != codes.Unavailable {
+			t.Error("code: expected Unavailable received", err)
+		}
+	}
+}
+
+func TestSpdk_PoolConnect(t *testing.T) {
+	dir := t.TempDir()
+	sockets := []string{filepath.Join(dir, "spdk0.sock"), filepath.Join(dir, "spdk1.sock")}
+	pool, err := NewPool(sockets, WithLogger(NopLogger{}))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	ctx := context.Background()
+	if err := pool.Connect(ctx); status.Code(err) != codes.Unavailable {
+		t.Error("code: expected", codes.Unavailable, "received", err)
+	}
+
+	ln, err := net.Listen("unix", sockets[1])
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer ln.Close()
+	if err := pool.Connect(ctx); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	now := time.Now().UnixNano()
+	if pool.endpoints[0].failedUntil <= now || pool.endpoints[1].failedUntil != 0 {
+		t.Error("cooldown: unexpected", pool.endpoints[0].failedUntil, pool.endpoints[1].failedUntil)
+	}
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CallRaw sends requestJSON to SPDK exactly as given, e.g. a request captured
+// from another client, and returns the response bytes undecoded. Matching the
+// id and checking for an error object is left to the caller. Like Batch it
+// always uses a dedicated connection with the usual framing and deadlines, so
+// notifications, which SPDK never answers, should go through Notify instead.
+func (r *Client) CallRaw(ctx context.Context, requestJSON []byte) ([]byte, error) {
+	if !json.Valid(requestJSON) {
+		return nil, status.Error(codes.InvalidArgument, "raw request is not valid JSON")
+	}
+	if !r.noRequestLog {
+		r.logger.Printf("Sending to SPDK: %s", r.redact(requestJSON))
+	}
+
+	release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+	raw, err := r.communicate(ctx, requestJSON)
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	if !r.noResponseLog {
+		r.logger.Printf("Received from SPDK: %s", r.redact(raw))
+	}
+	return raw, nil
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_CallRaw(t *testing.T) {
+	tests := map[string]struct {
+		request  string
+		want     string
+		wantCode codes.Code
+		accepted int32
+	}{
+		"captured request": {
+			`{"jsonrpc":"2.0","id":42,"method":"bdev_get_bdevs","params":{"name":"Malloc0"}}`,
+			`{"jsonrpc":"2.0","id":42,"result":[{"name":"Malloc0"}]}`,
+			codes.OK,
+			1,
+		},
+		"error response is returned as is": {
+			`{"jsonrpc":"2.0","id":7,"method":"bdev_get_bdevs","params":{"name":"Missing"}}`,
+			`{"jsonrpc":"2.0","id":7,"error":{"code":-19,"message":"No such device"}}`,
+			codes.OK,
+			1,
+		},
+		"invalid json": {
+			`{"jsonrpc":"2.0",`,
+			"",
+			codes.InvalidArgument,
+			0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			want := tt.want
+			sent := make(chan RPCRequest, 1)
+			accepted := serve(ln, func(req RPCRequest) string {
+				sent <- req
+				return want
+			})
+
+			got, err := client.CallRaw(context.Background(), []byte(tt.request))
+			if status.Code(err) != tt.wantCode {
+				t.Error("code: expected", tt.wantCode, "received", err)
+			}
+			if string(got) != tt.want {
+				t.Error("response: expected", tt.want, "received", string(got))
+			}
+			if n := atomic.LoadInt32(accepted); n != tt.accepted {
+				t.Error("accepted: expected", tt.accepted, "received", n)
+			}
+			if tt.accepted == 0 {
+				return
+			}
+			var wantReq RPCRequest
+			_ = json.Unmarshal([]byte(tt.request), &wantReq)
+			if req := <-sent; req.ID != wantReq.ID || req.Method != wantReq.Method {
+				t.Error("request: expected", wantReq, "received", req)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SensitiveFields lists the JSON keys of the params models that carry secrets,
+// e.g. the inline keys of crypto bdevs and accel keys or TLS pre-shared keys,
+// to be masked with WithRedactedFields(SensitiveFields...)
+var SensitiveFields = []string{"key", "key2", "psk"}
+
+// redactedValue replaces the value of every redacted field in logs
+const redactedValue = "***"
+
+// redact returns the copy of a request or response that is logged, with the
+// configured fields replaced and then passed through the log redactor, the
+// original bytes are never modified
+func (r *Client) redact(data []byte) []byte {
+	redacted := r.redactFields(data)
+	if r.logRedactor == nil {
+		return redacted
+	}
+	// the redactor may work in place, data is what goes over the wire
+	return r.logRedactor(append([]byte(nil), redacted...))
+}
+
+// redactFields returns a copy of the JSON document with values of the
+// configured fields replaced, or data itself when there is nothing to replace
+func (r *Client) redactFields(data []byte) []byte {
+	if len(r.redacted) == 0 {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return data
+	}
+	redacted, err := json.Marshal(redactValue(doc, r.redacted))
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// redactValue walks nested objects and arrays replacing matching keys
+func redactValue(v interface{}, fields map[string]struct{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if _, ok := fields[key]; ok {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(value, fields)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value, fields)
+		}
+	}
+	return v
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exchangeWithRetry repeats the exchange while the transport reports
+// codes.Unavailable, e.g. connection refused during an SPDK restart. Only
+// failures before the request left the client are reported that way, a
+// connection lost after it is codes.Aborted since SPDK may have run the
+// request. JSON-RPC application errors are part of a successful exchange
+// and therefore never retried.
+func (r *Client) exchangeWithRetry(ctx context.Context, method string, exchange func(context.Context) (RPCResponse, error)) (RPCResponse, error) {
+	response, err := exchange(ctx)
+	for attempt := 1; attempt < r.retryAttempts && status.Code(err) == codes.Unavailable; attempt++ {
+		delay := r.backoff(attempt)
+		r.logger.Printf("Retrying %s (attempt %d of %d) in %v after: %v", method, attempt+1, r.retryAttempts, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+		response, err = exchange(ctx)
+	}
+	return response, err
+}
+
+// IsRetryable reports whether an error returned by Call is worth retrying:
+// SPDK could not be reached, the connection broke or timed out, or SPDK
+// answered with an error mapped to codes.Unavailable or DeadlineExceeded,
+// e.g. EAGAIN. Any other JSON-RPC error, such as invalid params, an unknown
+// method or an existing object, fails the same way again, as do canceled
+// calls and requests that could not be encoded.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return retryableCode(rpcErr.GRPCStatus().Code())
+	}
+	if isBrokenPipe(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return retryableCode(status.Code(err))
+}
+
+func retryableCode(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// MaxRetryDelay caps the backoff between retries, unless the base delay
+// given to WithRetry is already longer
+const MaxRetryDelay = 30 * time.Second
+
+// backoff doubles the base delay on every attempt, up to MaxRetryDelay, and
+// picks a random duration in its upper half so that clients restarted
+// together spread out
+func (r *Client) backoff(attempt int) time.Duration {
+	if r.retryDelay <= 0 {
+		return 0
+	}
+	limit := MaxRetryDelay
+	if r.retryDelay > limit {
+		limit = r.retryDelay
+	}
+	delay := r.retryDelay
+	// doubling step by step rather than shifting cannot overflow
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // jitter needs no cryptographic randomness
+}
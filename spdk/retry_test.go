@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_IsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil":                {nil, false},
+		"unreachable":        {status.Error(codes.Unavailable, "failed to connect to SPDK"), true},
+		"deadline":           {fmt.Errorf("bdev_get_bdevs: %w", status.FromContextError(context.DeadlineExceeded).Err()), true},
+		"canceled":           {status.FromContextError(context.Canceled).Err(), false},
+		"lost after send":    {status.Error(codes.Aborted, "connection to SPDK closed before response was received"), false},
+		"broken pipe":        {&net.OpError{Op: "write", Net: "unix", Err: os.NewSyscallError("write", syscall.EPIPE)}, true},
+		"connection reset":   {fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		"socket timeout":     {&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, true},
+		"try again":          {&RPCError{Method: "bdev_lvol_resize", Code: EAGAINCode, Message: "Resource temporarily unavailable"}, true},
+		"invalid params":     {&RPCError{Method: "bdev_malloc_create", Code: InvalidParamsCode, Message: "Invalid parameters"}, false},
+		"method not found":   {&RPCError{Method: "bdev_foo", Code: MethodNotFoundCode, Message: "Method not found"}, false},
+		"already exists":     {wrapRPCError(&RPCError{Code: EEXISTCode, Message: "File exists"}, ErrBdevExists, EEXISTCode), false},
+		"unknown":            {errors.New("something else"), false},
+		"id mismatch":        {fmt.Errorf("bdev_get_bdevs: %w", ErrResponseIDMismatch), false},
+		"no response at all": {ErrEmptyResponse, false},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Error("retryable: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestSpdk_Backoff(t *testing.T) {
+	tests := map[string]struct {
+		baseDelay time.Duration
+		attempt   int
+		min       time.Duration
+		max       time.Duration
+	}{
+		"first retry":         {100 * time.Millisecond, 1, 50 * time.Millisecond, 100 * time.Millisecond},
+		"doubled":             {100 * time.Millisecond, 3, 200 * time.Millisecond, 400 * time.Millisecond},
+		"capped":              {100 * time.Millisecond, 20, MaxRetryDelay / 2, MaxRetryDelay},
+		"beyond shift width":  {100 * time.Millisecond, 1000, MaxRetryDelay / 2, MaxRetryDelay},
+		"base beyond the cap": {time.Minute, 5, 30 * time.Second, time.Minute},
+		"no delay":            {0, 1000, 0, 0},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient("/var/tmp/spdk.sock", WithLogger(NopLogger{}), WithRetry(tt.attempt+1, tt.baseDelay))
+			for i := 0; i < 20; i++ {
+				if got := client.backoff(tt.attempt); got < tt.min || got > tt.max {
+					t.Fatal("delay: expected between", tt.min, "and", tt.max, "received", got)
+				}
+			}
+		})
+	}
+}
+
+func TestSpdk_RetryAfterSend(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}),
+		WithMultiplexing(), WithRetry(3, time.Millisecond))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	defer client.Close()
+	var requests int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// SPDK reads and runs the request, then drops the connection mid-response
+			var req RPCRequest
+			if err := json.NewDecoder(conn).Decode(&req); err == nil {
+				atomic.AddInt32(&requests, 1)
+				_, _ = io.WriteString(conn, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"res`, req.ID))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	err := client.Call(context.Background(), "bdev_malloc_create", nil, nil)
+	if status.Code(err) != codes.Aborted {
+		t.Error("code: expected", codes.Aborted, "received", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Error("requests: expected 1 received", n)
+	}
+}
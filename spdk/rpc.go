@@ -7,16 +7,70 @@ package spdk
 import (
 	"encoding/json"
 	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // JSONRPCVersion holds the current version of json RPC protocol
 const JSONRPCVersion = "2.0"
 
-// RPCRequest holds the parameters required to request struct
+// JSON-RPC 2.0 reserved error codes
+const (
+	ParseErrorCode     = -32700
+	InvalidRequestCode = -32600
+	MethodNotFoundCode = -32601
+	InvalidParamsCode  = -32602
+	InternalErrorCode  = -32603
+)
+
+// Error codes SPDK reports as negated Linux errno values
+const (
+	EPERMCode      = -1
+	ENOENTCode     = -2
+	ENXIOCode      = -6
+	EIOCode        = -5
+	EAGAINCode     = -11
+	ENOMEMCode     = -12
+	EACCESCode     = -13
+	EBUSYCode      = -16
+	EEXISTCode     = -17
+	ENODEVCode     = -19
+	EINVALCode     = -22
+	ENOSPCCode     = -28
+	EOPNOTSUPPCode = -95
+	ETIMEDOUTCode  = -110
+)
+
+// rpcErrorCodes maps SPDK error codes to the closest gRPC codes
+var rpcErrorCodes = map[int]codes.Code{
+	ParseErrorCode:     codes.Internal,
+	InvalidRequestCode: codes.InvalidArgument,
+	MethodNotFoundCode: codes.Unimplemented,
+	InvalidParamsCode:  codes.InvalidArgument,
+	InternalErrorCode:  codes.Internal,
+	EPERMCode:          codes.PermissionDenied,
+	ENOENTCode:         codes.NotFound,
+	ENXIOCode:          codes.NotFound,
+	EIOCode:            codes.Internal,
+	EAGAINCode:         codes.Unavailable,
+	ENOMEMCode:         codes.ResourceExhausted,
+	EACCESCode:         codes.PermissionDenied,
+	EBUSYCode:          codes.Unavailable,
+	EEXISTCode:         codes.AlreadyExists,
+	ENODEVCode:         codes.NotFound,
+	EINVALCode:         codes.InvalidArgument,
+	ENOSPCCode:         codes.ResourceExhausted,
+	EOPNOTSUPPCode:     codes.Unimplemented,
+	ETIMEDOUTCode:      codes.DeadlineExceeded,
+}
+
+// RPCRequest holds the parameters required to request struct,
+// a zero ID is omitted which turns the request into a notification
 type RPCRequest struct {
-	RPCVersion string      `json:"jsonrpc"`
+	RPCVersion string      `json:"jsonrpc,omitempty"`
 	Method     string      `json:"method"`
-	ID         uint64      `json:"id"`
+	ID         uint64      `json:"id,omitempty"`
 	Params     interface{} `json:"params,omitempty"`
 }
 
@@ -28,13 +82,72 @@ type RPCResponse struct {
 	Error          RPCError        `json:"error"`
 }
 
-// RPCError holds the parameters of the error structs
+// RPCError holds the parameters of the error structs.
+// Call returns it as *RPCError so callers can use errors.As
+// to branch on the SPDK error code
 type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Method  string          `json:"-"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
 }
 
 // Error returns formatted string of RPC error
 func (e RPCError) Error() string {
-	return fmt.Sprintf("Code=%d Msg=%s", e.Code, e.Message)
+	if e.Method == "" {
+		return fmt.Sprintf("Code=%d Msg=%s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: json response error: %s", e.Method, e.Message)
+}
+
+// ResultDecodeError is returned when a result SPDK sent does not fit the
+// result value passed to Call or Batch, Raw holds the result exactly as it
+// was received so a schema mismatch can be logged and compared
+type ResultDecodeError struct {
+	Method string
+	Raw    json.RawMessage
+	Err    error
+}
+
+// Error returns the method followed by the decoding error
+func (e *ResultDecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Err)
+}
+
+// Unwrap returns the decoding error
+func (e *ResultDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeData unmarshals the structured data SPDK attached to the error into v,
+// it leaves v untouched when there is no data
+func (e RPCError) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// DataMap decodes the data SPDK attached to the error as a generic object, for
+// callers without a struct for it. Data that is not an object, e.g. a bare
+// string or number, is an error, use DecodeData or Data for those.
+func (e RPCError) DataMap() (map[string]interface{}, error) {
+	if len(e.Data) == 0 {
+		return nil, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, fmt.Errorf("error data is not an object: %s", e.Data)
+	}
+	return data, nil
+}
+
+// GRPCStatus converts the SPDK error code into a gRPC status,
+// so status.Code and status.FromError work on errors returned by Call
+func (e RPCError) GRPCStatus() *status.Status {
+	code, ok := rpcErrorCodes[e.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.New(code, e.Error())
 }
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpdk_RPCErrorGRPCStatus(t *testing.T) {
+	tests := map[string]struct {
+		code int
+		want codes.Code
+	}{
+		"invalid params": {
+			InvalidParamsCode,
+			codes.InvalidArgument,
+		},
+		"method not found": {
+			MethodNotFoundCode,
+			codes.Unimplemented,
+		},
+		"no such device": {
+			ENODEVCode,
+			codes.NotFound,
+		},
+		"file exists": {
+			EEXISTCode,
+			codes.AlreadyExists,
+		},
+		"unmapped code": {
+			-1000,
+			codes.Unknown,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &RPCError{Method: "bdev_get_bdevs", Code: tt.code, Message: name})
+			if code := status.Code(err); code != tt.want {
+				t.Error("response: expected", tt.want, "received", code)
+			}
+		})
+	}
+}
+
+func TestSpdk_RPCErrorDecodeData(t *testing.T) {
+	var data map[string]int
+	if err := (RPCError{Code: InvalidParamsCode}).DecodeData(&data); err != nil || data != nil {
+		t.Error("no data: expected untouched received", data, err)
+	}
+	if err := (RPCError{Data: json.RawMessage(`{"nsid":1}`)}).DecodeData(&data); err != nil || data["nsid"] != 1 {
+		t.Error("data: expected nsid 1 received", data, err)
+	}
+}
+
+func TestSpdk_RPCErrorDataMap(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		"object": {
+			`{"nsid":1,"bdev_name":"Malloc0"}`,
+			map[string]interface{}{"nsid": float64(1), "bdev_name": "Malloc0"},
+			false,
+		},
+		"no data": {
+			"",
+			nil,
+			false,
+		},
+		"bare string": {
+			`"Namespace already in use"`,
+			nil,
+			true,
+		},
+		"bare number": {
+			`17`,
+			nil,
+			true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rpcErr := RPCError{Code: EEXISTCode, Message: "File exists", Data: json.RawMessage(tt.data)}
+			got, err := rpcErr.DataMap()
+			if (err != nil) != tt.wantErr {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+			// the raw bytes stay available for callers with their own type
+			if string(rpcErr.Data) != tt.data {
+				t.Error("data: expected", tt.data, "received", string(rpcErr.Data))
+			}
+		})
+	}
+
+	var message string
+	if err := (RPCError{Data: json.RawMessage(`"Namespace already in use"`)}).DecodeData(&message); err != nil || message != "Namespace already in use" {
+		t.Error("bare string: unexpected", message, err)
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdktest provides utilities for testing code built on the spdk package
+package spdktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/gospdk/spdk"
+)
+
+// bdevDeleteMethods maps the product name bdev_get_bdevs reports for each bdev
+// type to the method deleting a bdev of that type by name
+var bdevDeleteMethods = map[string]string{
+	"Malloc disk":    "bdev_malloc_delete",
+	"Null disk":      "bdev_null_delete",
+	"AIO disk":       "bdev_aio_delete",
+	"URING bdev":     "bdev_uring_delete",
+	"Raid Volume":    "bdev_raid_delete",
+	"Logical Volume": "bdev_lvol_delete",
+	"crypto":         "bdev_crypto_delete",
+	"compress":       "bdev_compress_delete",
+	"passthru":       "bdev_passthru_delete",
+	"SPDK OCF":       "bdev_ocf_delete",
+	"delay":          "bdev_delay_delete",
+	"error":          "bdev_error_delete",
+}
+
+// nvmeProductName is reported for the namespaces of attached NVMe controllers,
+// which go away by detaching the controller
+const nvmeProductName = "NVMe disk"
+
+// bdevNameParams holds the name of the bdev to delete
+type bdevNameParams struct {
+	Name string `json:"name"`
+}
+
+// lvstore is an lvol store as reported by bdev_lvol_get_lvstores
+type lvstore struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// lvstoreUUIDParams holds the uuid of the lvol store to delete
+type lvstoreUUIDParams struct {
+	UUID string `json:"uuid"`
+}
+
+// DeleteAllBdevs deletes every bdev SPDK reports, e.g. to clean up after a
+// test that failed halfway, with the delete method matching each bdev type.
+// Bdevs layered on others go first, NVMe namespaces are removed by detaching
+// their controller and lvol stores are deleted once their lvols are gone.
+// Bdevs that disappear concurrently are not an error. Every deletion is
+// attempted and the failures, including bdevs of unknown types and any bdevs
+// left over, are returned together.
+func DeleteAllBdevs(ctx context.Context, client spdk.JSONRPC) error {
+	var errs multiError
+	failed := make(map[string]bool)
+	attempted := make(map[string]bool)
+	for {
+		bdevs, err := spdk.NewBdevService(client).GetBdevs(ctx, "")
+		if err != nil {
+			return append(errs, err).err()
+		}
+		var left []string
+		progress := false
+		detached := make(map[string]bool)
+		for _, bdev := range bdevs {
+			if bdev.Claimed || failed[bdev.Name] {
+				left = append(left, bdev.Name)
+				continue
+			}
+			if attempted[bdev.Name] {
+				// deleted without error but still listed, do not loop on it
+				failed[bdev.Name] = true
+				left = append(left, bdev.Name)
+				continue
+			}
+			method, name := deleteCall(bdev)
+			if method == "" {
+				failed[bdev.Name] = true
+				errs = append(errs, fmt.Errorf("%s: no delete method for product %q", bdev.Name, bdev.ProductName))
+				continue
+			}
+			attempted[bdev.Name] = true
+			if detached[name] {
+				continue
+			}
+			detached[name] = true
+			err := client.Call(ctx, method, &bdevNameParams{Name: name}, nil)
+			if err != nil && !isNotFound(err) {
+				failed[bdev.Name] = true
+				errs = append(errs, fmt.Errorf("%s: %w", bdev.Name, err))
+				continue
+			}
+			progress = true
+		}
+		if len(bdevs) == 0 {
+			return errs.err()
+		}
+		if !progress {
+			// what remains is only held by lvol stores, if anything
+			deleted, err := deleteLvstores(ctx, client)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			progress = deleted
+		}
+		if !progress {
+			sort.Strings(left)
+			return append(errs, fmt.Errorf("bdevs left over: %s", strings.Join(left, ", "))).err()
+		}
+	}
+}
+
+// deleteCall returns the method deleting bdev and the name to pass to it,
+// or an empty method when the bdev type is unknown
+func deleteCall(bdev spdk.Bdev) (method string, name string) {
+	if bdev.ProductName == nvmeProductName {
+		// namespace bdevs are named after their controller, e.g. Nvme0n1
+		if i := strings.LastIndex(bdev.Name, "n"); i > 0 {
+			return "bdev_nvme_detach_controller", bdev.Name[:i]
+		}
+		return "", ""
+	}
+	return bdevDeleteMethods[bdev.ProductName], bdev.Name
+}
+
+// deleteLvstores deletes every lvol store, releasing the bdevs they claim,
+// and reports whether any was deleted
+func deleteLvstores(ctx context.Context, client spdk.JSONRPC) (bool, error) {
+	var lvstores []lvstore
+	if err := client.Call(ctx, "bdev_lvol_get_lvstores", nil, &lvstores); err != nil {
+		return false, err
+	}
+	var errs multiError
+	deleted := false
+	for _, lvs := range lvstores {
+		err := client.Call(ctx, "bdev_lvol_delete_lvstore", &lvstoreUUIDParams{UUID: lvs.UUID}, nil)
+		if err != nil && !isNotFound(err) {
+			errs = append(errs, fmt.Errorf("lvstore %s: %w", lvs.Name, err))
+			continue
+		}
+		deleted = true
+	}
+	return deleted, errs.err()
+}
+
+// isNotFound reports whether SPDK failed because the object is already gone
+func isNotFound(err error) bool {
+	var rpcErr *spdk.RPCError
+	return errors.As(err, &rpcErr) && (rpcErr.Code == spdk.ENODEVCode || rpcErr.Code == spdk.ENOENTCode)
+}
+
+// multiError combines the errors of all attempts, errors.Is and errors.As
+// match any of them
+type multiError []error
+
+// err returns nil when there were no errors
+func (m multiError) err() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error returns the messages of all errors
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target
+func (m multiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
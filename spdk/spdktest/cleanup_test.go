@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdktest provides utilities for testing code built on the spdk package
+package spdktest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opiproject/gospdk/spdk"
+)
+
+// fakeBdevs emulates the bdev layer, deleting a bdev releases its base bdevs
+type fakeBdevs struct {
+	mu       sync.Mutex
+	bdevs    map[string]spdk.Bdev
+	bases    map[string][]string
+	lvstores map[string]string
+	calls    []string
+	fail     map[string]error
+}
+
+func (f *fakeBdevs) handle(method string, params json.RawMessage) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var p struct {
+		Name string `json:"name"`
+		UUID string `json:"uuid"`
+	}
+	_ = json.Unmarshal(params, &p)
+	switch method {
+	case "bdev_get_bdevs":
+		bdevs := []spdk.Bdev{}
+		for _, bdev := range f.bdevs {
+			bdevs = append(bdevs, bdev)
+		}
+		sort.Slice(bdevs, func(i, j int) bool { return bdevs[i].Name < bdevs[j].Name })
+		return bdevs, nil
+	case "bdev_lvol_get_lvstores":
+		lvstores := []lvstore{}
+		for uuid := range f.lvstores {
+			lvstores = append(lvstores, lvstore{UUID: uuid, Name: "lvs0"})
+		}
+		return lvstores, nil
+	case "bdev_lvol_delete_lvstore":
+		f.calls = append(f.calls, method+" "+p.UUID)
+		f.release(f.lvstores[p.UUID])
+		delete(f.lvstores, p.UUID)
+		return true, nil
+	case "bdev_nvme_detach_controller":
+		f.calls = append(f.calls, method+" "+p.Name)
+		for name := range f.bdevs {
+			if strings.HasPrefix(name, p.Name+"n") {
+				delete(f.bdevs, name)
+			}
+		}
+		return true, nil
+	}
+	f.calls = append(f.calls, method+" "+p.Name)
+	if err := f.fail[p.Name]; err != nil {
+		return nil, err
+	}
+	if _, ok := f.bdevs[p.Name]; !ok {
+		return nil, &spdk.RPCError{Code: spdk.ENODEVCode, Message: "No such device"}
+	}
+	delete(f.bdevs, p.Name)
+	for _, base := range f.bases[p.Name] {
+		f.release(base)
+	}
+	return true, nil
+}
+
+func (f *fakeBdevs) release(name string) {
+	if bdev, ok := f.bdevs[name]; ok {
+		bdev.Claimed = false
+		f.bdevs[name] = bdev
+	}
+}
+
+func TestSpdkTest_DeleteAllBdevs(t *testing.T) {
+	busy := &spdk.RPCError{Code: spdk.EBUSYCode, Message: "Device or resource busy"}
+	tests := map[string]struct {
+		bdevs     []spdk.Bdev
+		fail      map[string]error
+		wantCalls []string
+		wantErr   *spdk.RPCError
+		wantLeft  []string
+	}{
+		"layered bdevs": {
+			[]spdk.Bdev{
+				{Name: "Malloc0", ProductName: "Malloc disk", Claimed: true},
+				{Name: "Crypto0", ProductName: "crypto"},
+				{Name: "Malloc1", ProductName: "Malloc disk", Claimed: true},
+				{Name: "lvs0/lvol0", ProductName: "Logical Volume"},
+				{Name: "Nvme0n1", ProductName: "NVMe disk"},
+				{Name: "Nvme0n2", ProductName: "NVMe disk"},
+			},
+			nil,
+			[]string{
+				"bdev_crypto_delete Crypto0",
+				"bdev_nvme_detach_controller Nvme0",
+				"bdev_lvol_delete lvs0/lvol0",
+				"bdev_malloc_delete Malloc0",
+				"bdev_lvol_delete_lvstore lvs-uuid",
+				"bdev_malloc_delete Malloc1",
+			},
+			nil,
+			nil,
+		},
+		"failures are combined": {
+			[]spdk.Bdev{
+				{Name: "Foo0", ProductName: "foo"},
+				{Name: "Malloc0", ProductName: "Malloc disk"},
+				{Name: "Null0", ProductName: "Null disk"},
+			},
+			map[string]error{"Malloc0": busy},
+			[]string{
+				"bdev_malloc_delete Malloc0",
+				"bdev_null_delete Null0",
+				"bdev_lvol_delete_lvstore lvs-uuid",
+			},
+			busy,
+			[]string{"Foo0", "Malloc0"},
+		},
+		"already gone": {
+			[]spdk.Bdev{{Name: "Null0", ProductName: "Null disk"}},
+			map[string]error{"Null0": &spdk.RPCError{Code: spdk.ENODEVCode, Message: "No such device"}},
+			[]string{"bdev_null_delete Null0", "bdev_lvol_delete_lvstore lvs-uuid"},
+			nil,
+			[]string{"Null0"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := &fakeBdevs{
+				bdevs:    map[string]spdk.Bdev{},
+				bases:    map[string][]string{"Crypto0": {"Malloc0"}},
+				lvstores: map[string]string{"lvs-uuid": "Malloc1"},
+				fail:     tt.fail,
+			}
+			for _, bdev := range tt.bdevs {
+				fake.bdevs[bdev.Name] = bdev
+			}
+			socket, cleanup := NewServer(fake.handle)
+			defer cleanup()
+			client := spdk.NewClient(socket, spdk.WithLogger(spdk.NopLogger{}))
+
+			err := DeleteAllBdevs(context.Background(), client)
+			if (err != nil) != (tt.wantErr != nil || tt.wantLeft != nil) {
+				t.Error("error: expected", tt.wantErr, tt.wantLeft, "received", err)
+			}
+			var rpcErr *spdk.RPCError
+			if tt.wantErr != nil && (!errors.As(err, &rpcErr) || rpcErr.Code != tt.wantErr.Code) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			for _, name := range tt.wantLeft {
+				if !strings.Contains(err.Error(), name) {
+					t.Error("error: expected", name, "to be reported received", err)
+				}
+			}
+			if !reflect.DeepEqual(fake.calls, tt.wantCalls) {
+				t.Error("calls: expected", tt.wantCalls, "received", fake.calls)
+			}
+		})
+	}
+}
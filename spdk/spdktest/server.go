@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdktest provides utilities for testing code built on the spdk package
+package spdktest
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/opiproject/gospdk/spdk"
+)
+
+// Handler answers a single request, an error of type *spdk.RPCError is sent
+// back with its code, message and data while any other error is reported as
+// the JSON-RPC internal error
+type Handler func(method string, params json.RawMessage) (interface{}, error)
+
+// RawResponse is written to the connection verbatim instead of a well-formed
+// response when returned as a Handler result, e.g. to send malformed JSON or
+// a mismatching id
+type RawResponse string
+
+// request is the wire format of a single JSON-RPC request
+type request struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// response is the wire format of a single JSON-RPC response
+type response struct {
+	JSONRPCVersion string          `json:"jsonrpc"`
+	ID             uint64          `json:"id"`
+	Result         json.RawMessage `json:"result,omitempty"`
+	Error          *spdk.RPCError  `json:"error,omitempty"`
+}
+
+// NewServer listens on a temporary unix socket and answers every request it
+// receives with handler. It speaks the same framing as SPDK: requests are
+// read one JSON object at a time until the client half-closes or closes the
+// connection. The returned cleanup stops the server and removes the socket.
+func NewServer(handler Handler) (socketPath string, cleanup func()) {
+	dir, err := os.MkdirTemp("", "spdktest")
+	if err != nil {
+		log.Panic(err)
+	}
+	socketPath = filepath.Join(dir, "spdk.sock")
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		log.Panic(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn, handler)
+		}
+	}()
+	return socketPath, func() {
+		_ = ln.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// serve answers requests on a single connection until it is closed
+func serve(conn net.Conn, handler Handler) {
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := decoder.Decode(&req); err != nil {
+			return
+		}
+		result, err := handler(req.Method, req.Params)
+		if req.ID == 0 {
+			// notifications are never answered
+			continue
+		}
+		if raw, ok := result.(RawResponse); ok && err == nil {
+			if _, err := conn.Write([]byte(raw)); err != nil {
+				return
+			}
+			continue
+		}
+		resp := response{JSONRPCVersion: spdk.JSONRPCVersion, ID: req.ID}
+		if err != nil {
+			var rpcErr *spdk.RPCError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &spdk.RPCError{Code: spdk.InternalErrorCode, Message: err.Error()}
+			}
+			resp.Error = rpcErr
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Result = nil
+			resp.Error = &spdk.RPCError{Code: spdk.InternalErrorCode, Message: err.Error()}
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdktest provides utilities for testing code built on the spdk package
+package spdktest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/gospdk/spdk"
+)
+
+func TestSpdkTest_NewServer(t *testing.T) {
+	tests := map[string]struct {
+		handler Handler
+		result  interface{}
+		errCode codes.Code
+		rpcCode int
+	}{
+		"valid result": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return true, nil
+			},
+			true,
+			codes.OK,
+			0,
+		},
+		"false result": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return false, nil
+			},
+			false,
+			codes.OK,
+			0,
+		},
+		"rpc error code": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return nil, &spdk.RPCError{Code: spdk.ENODEVCode, Message: "No such device"}
+			},
+			false,
+			codes.NotFound,
+			spdk.ENODEVCode,
+		},
+		"plain error": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return nil, errors.New("boom")
+			},
+			false,
+			codes.Internal,
+			spdk.InternalErrorCode,
+		},
+		"timeout": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				time.Sleep(200 * time.Millisecond)
+				return true, nil
+			},
+			false,
+			codes.DeadlineExceeded,
+			0,
+		},
+		"malformed response": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return RawResponse(`{"jsonrpc":"2.0","id":`), nil
+			},
+			false,
+			codes.Unknown,
+			0,
+		},
+		"id mismatch": {
+			func(_ string, _ json.RawMessage) (interface{}, error) {
+				return RawResponse(`{"jsonrpc":"2.0","id":999,"result":true}`), nil
+			},
+			false,
+			codes.Internal,
+			0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			socket, cleanup := NewServer(tt.handler)
+			defer cleanup()
+			client := spdk.NewClient(socket, spdk.WithLogger(spdk.NopLogger{}), spdk.WithTimeout(100*time.Millisecond))
+
+			var result bool
+			err := client.Call(context.Background(), "bdev_malloc_delete", &spdk.BdevMallocDeleteParams{Name: "Malloc0"}, &result)
+			if code := status.Code(err); code != tt.errCode {
+				t.Error("code: expected", tt.errCode, "received", code, err)
+			}
+			var rpcErr *spdk.RPCError
+			if tt.rpcCode != 0 && (!errors.As(err, &rpcErr) || rpcErr.Code != tt.rpcCode) {
+				t.Error("rpc error: expected", tt.rpcCode, "received", err)
+			}
+			if result != tt.result {
+				t.Error("response: expected", tt.result, "received", result)
+			}
+		})
+	}
+}
+
+func TestSpdkTest_NewServerParams(t *testing.T) {
+	received := make(chan string, 1)
+	socket, cleanup := NewServer(func(method string, params json.RawMessage) (interface{}, error) {
+		received <- method + " " + string(params)
+		return "Malloc0", nil
+	})
+	defer cleanup()
+	client := spdk.NewClient(socket, spdk.WithLogger(spdk.NopLogger{}))
+
+	var name string
+	params := spdk.BdevMalloCreateParams{NumBlocks: 64, BlockSize: 512, Name: "Malloc0"}
+	if err := client.Call(context.Background(), "bdev_malloc_create", &params, &name); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if name != "Malloc0" {
+		t.Error("response: expected Malloc0 received", name)
+	}
+	expected := `bdev_malloc_create {"num_blocks":64,"block_size":512,"name":"Malloc0","uuid":""}`
+	if got := <-received; got != expected {
+		t.Error("params: expected", expected, "received", got)
+	}
+}
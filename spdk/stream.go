@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// streams reports whether the request of method is encoded onto the connection
+func (r *Client) streams(method string) bool {
+	if !r.streamRequests || r.persistent || r.multiplex || r.onRequest != nil {
+		return false
+	}
+	if r.streamMethods == nil {
+		return true
+	}
+	_, ok := r.streamMethods[method]
+	return ok
+}
+
+// streamedCall is rawCall for a request that is never held in memory as a
+// whole, so only its method and id are logged
+func (r *Client) streamedCall(ctx context.Context, id uint64, method string, args interface{}) (json.RawMessage, error) {
+	request := RPCRequest{
+		RPCVersion: r.rpcVersion,
+		ID:         id,
+		Method:     method,
+		Params:     omitNilParams(args),
+	}
+	if !r.noRequestLog {
+		r.logger.Printf("Sending to SPDK method=%s id=%d: streamed request", method, id)
+	}
+	start := time.Now()
+	response, err := r.exchangeWithRetry(ctx, method, func(ctx context.Context) (RPCResponse, error) {
+		return r.exchangeStreamed(ctx, request)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return r.checkResponse(method, id, response, start)
+}
+
+// exchangeStreamed encodes the request onto a dedicated connection and
+// decodes a single response
+func (r *Client) exchangeStreamed(ctx context.Context, request RPCRequest) (RPCResponse, error) {
+	release, err := r.acquire(ctx)
+	if err != nil {
+		return RPCResponse{}, err
+	}
+	defer release()
+	resp, err := r.transmit(ctx, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(request)
+	})
+	if err != nil {
+		return RPCResponse{}, err
+	}
+	return decodeResponse(resp)
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSpdk_WithStreamedRequests(t *testing.T) {
+	config := strings.Repeat("x", 4<<20)
+	tests := map[string]struct {
+		methods  []string
+		method   string
+		streamed bool
+	}{
+		"all methods": {
+			nil,
+			"load_config",
+			true,
+		},
+		"listed method": {
+			[]string{"load_config"},
+			"load_config",
+			true,
+		},
+		"unlisted method": {
+			[]string{"load_config"},
+			"save_config",
+			false,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(logger), WithStreamedRequests(tt.methods...))
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			serve(ln, func(req RPCRequest) string {
+				params, _ := req.Params.(map[string]interface{})
+				received, _ := params["config"].(string)
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%d}`, req.ID, len(received))
+			})
+
+			var result int
+			err := client.Call(context.Background(), tt.method, map[string]string{"config": config}, &result)
+			if err != nil {
+				t.Fatal("error: expected nil received", err)
+			}
+			if result != len(config) {
+				t.Error("result: expected", len(config), "received", result)
+			}
+			logger.mu.Lock()
+			defer logger.mu.Unlock()
+			var sent string
+			for _, line := range logger.lines {
+				if strings.HasPrefix(line, "Sending to SPDK") {
+					sent = line
+				}
+			}
+			if sent == "" {
+				t.Fatal("log: expected the request to be logged")
+			}
+			streamed := strings.HasSuffix(sent, "streamed request")
+			if streamed != tt.streamed {
+				t.Error("streamed: expected", tt.streamed, "received", streamed)
+			}
+		})
+	}
+}
+
+func TestSpdk_WithStreamedRequestsError(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}), WithStreamedRequests())
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	serve(ln, func(req RPCRequest) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-19,"message":"No such device"}}`, req.ID)
+	})
+
+	err := client.Call(context.Background(), "bdev_get_bdevs", map[string]string{"name": "Malloc0"}, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -19 {
+		t.Error("error: expected code -19 received", err)
+	}
+	err = client.Call(context.Background(), "bdev_get_bdevs", map[string]interface{}{"name": make(chan int)}, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Error("error: expected an encoding error received", err)
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"io"
+)
+
+// tapWriter passes every chunk written to the connection to the tap
+type tapWriter struct {
+	w   io.Writer
+	tap WireTap
+}
+
+func (t tapWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if n > 0 {
+		t.tap("send", p[:n])
+	}
+	return n, err
+}
+
+// tapReader passes every chunk read from the connection to the tap
+type tapReader struct {
+	r   io.Reader
+	tap WireTap
+}
+
+func (t tapReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.tap("recv", p[:n])
+	}
+	return n, err
+}
+
+// tapConn wraps the reads of a persistent or multiplexed connection in a
+// tapReader, when a tap is set
+func (r *Client) tapConn(conn io.Reader) io.Reader {
+	if r.wireTap == nil {
+		return conn
+	}
+	return tapReader{r: conn, tap: r.wireTap}
+}
+
+// tapSent passes the part of buf a persistent or multiplexed connection took
+// to the tap, when one is set
+func (r *Client) tapSent(buf []byte, n int) {
+	if r.wireTap != nil && n > 0 {
+		r.wireTap("send", buf[:n])
+	}
+}
+
+// tapWrites makes write go through a tapWriter
+func tapWrites(write func(io.Writer) error, tap WireTap) func(io.Writer) error {
+	return func(w io.Writer) error {
+		return write(tapWriter{w: w, tap: tap})
+	}
+}
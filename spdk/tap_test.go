@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSpdk_WithWireTap(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+	}{
+		"buffered":    {nil},
+		"streamed":    {[]Option{WithStreamedRequests()}},
+		"persistent":  {[]Option{WithPersistentConnection()}},
+		"multiplexed": {[]Option{WithMultiplexing()}},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			tapped := map[string][]byte{}
+			tap := func(direction string, b []byte) {
+				mu.Lock()
+				defer mu.Unlock()
+				tapped[direction] = append(tapped[direction], b...)
+			}
+			opts := append([]Option{WithLogger(NopLogger{}), WithWireTap(tap)}, tt.opts...)
+			client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), opts...)
+			ln := client.StartUnixListener()
+			defer ln.Close()
+			defer client.Close()
+			var reply string
+			serve(ln, func(req RPCRequest) string {
+				reply = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"Crypto0"}`, req.ID)
+				return reply
+			})
+
+			var result string
+			params := CryptoParams{BaseBdevName: "Nvme0n1", Name: "Crypto0", Key: "0123456789abcdef"}
+			if err := client.Call(context.Background(), "bdev_crypto_create", &params, &result); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			var sent struct {
+				Method string       `json:"method"`
+				Params CryptoParams `json:"params"`
+			}
+			if err := json.Unmarshal(tapped["send"], &sent); err != nil {
+				t.Fatal("send: expected a request received", string(tapped["send"]), err)
+			}
+			if sent.Method != "bdev_crypto_create" || sent.Params != params {
+				t.Error("send: expected the unredacted request received", string(tapped["send"]))
+			}
+			if !bytes.Equal(tapped["recv"], []byte(reply)) {
+				t.Error("recv: expected", reply, "received", string(tapped["recv"]))
+			}
+		})
+	}
+}
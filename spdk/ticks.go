@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"time"
+)
+
+// ticksToDuration converts a tick count to a duration at the given tick rate,
+// it returns 0 when the tick rate is unknown
+func ticksToDuration(ticks, tickRate uint64) time.Duration {
+	if tickRate == 0 {
+		return 0
+	}
+	// split into whole seconds and remainder to avoid overflowing uint64
+	secs := ticks / tickRate
+	rem := ticks % tickRate
+	return time.Duration(secs)*time.Second + time.Duration(rem*uint64(time.Second)/tickRate)
+}
+
+// BusyPercent returns the share of busy ticks in busy plus idle ticks, as a
+// percentage. Use the difference between two samples of the counters to get
+// the utilization over that interval rather than since SPDK started.
+func BusyPercent(busy, idle uint64) float64 {
+	total := busy + idle
+	if total == 0 {
+		return 0
+	}
+	return float64(busy) * 100 / float64(total)
+}
+
+// TicksToDuration converts a tick count reported by SPDK to a duration using
+// the tick rate of the result, it returns 0 when the tick rate is unknown
+func (r *ThreadStats) TicksToDuration(ticks uint64) time.Duration {
+	return ticksToDuration(ticks, r.TickRate)
+}
+
+// TicksToDuration converts a tick count reported by SPDK to a duration using
+// the tick rate of the result, it returns 0 when the tick rate is unknown
+func (r *ReactorStats) TicksToDuration(ticks uint64) time.Duration {
+	return ticksToDuration(ticks, r.TickRate)
+}
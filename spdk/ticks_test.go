@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpdk_BusyPercent(t *testing.T) {
+	tests := map[string]struct {
+		busy uint64
+		idle uint64
+		want float64
+	}{
+		"no ticks":  {0, 0, 0},
+		"idle":      {0, 100, 0},
+		"quarter":   {25, 75, 25},
+		"saturated": {100, 0, 100},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := BusyPercent(tt.busy, tt.idle); got != tt.want {
+				t.Error("percent: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestSpdk_StatsTicksToDuration(t *testing.T) {
+	threads := ThreadStats{TickRate: 2000000000}
+	if got := threads.TicksToDuration(3000000000); got != 1500*time.Millisecond {
+		t.Error("threads: expected", 1500*time.Millisecond, "received", got)
+	}
+	reactors := ReactorStats{}
+	if got := reactors.TicksToDuration(100); got != 0 {
+		t.Error("reactors: expected", 0, "received", got)
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+)
+
+// versionPattern matches the version string SPDK reports, e.g. "SPDK v23.01.1-pre git sha1 7d6be525f"
+var versionPattern = regexp.MustCompile(`v(\d+)\.(\d+)(?:\.(\d+))?(\S*)(?:\s+git sha1\s+(\S+))?`)
+
+// UnmarshalJSON decodes the spdk_get_version result, components missing from
+// fields are parsed from the version string instead
+func (v *SpdkVersion) UnmarshalJSON(data []byte) error {
+	type plain SpdkVersion
+	if err := json.Unmarshal(data, (*plain)(v)); err != nil {
+		return err
+	}
+	if v.Fields == (SpdkVersionFields{}) {
+		v.Fields = parseVersionString(v.Version)
+	}
+	return nil
+}
+
+// AtLeast reports whether the version is major.minor or newer
+func (v SpdkVersion) AtLeast(major, minor int) bool {
+	if v.Fields.Major != major {
+		return v.Fields.Major > major
+	}
+	return v.Fields.Minor >= minor
+}
+
+// String returns the version string reported by SPDK
+func (v SpdkVersion) String() string {
+	return v.Version
+}
+
+// parseVersionString extracts the version components from an SPDK version
+// string, an unrecognised string yields zero fields
+func parseVersionString(version string) SpdkVersionFields {
+	var fields SpdkVersionFields
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return fields
+	}
+	fields.Major, _ = strconv.Atoi(m[1])
+	fields.Minor, _ = strconv.Atoi(m[2])
+	fields.Patch, _ = strconv.Atoi(m[3])
+	fields.Suffix = m[4]
+	fields.Commit = m[5]
+	return fields
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpdk_SpdkVersionUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		raw  string
+		want SpdkVersionFields
+	}{
+		"fields reported": {
+			`{"version":"SPDK v23.01.1-pre git sha1 7d6be525f","fields":{"major":23,"minor":1,"patch":1,"suffix":"-pre","commit":"7d6be525f"}}`,
+			SpdkVersionFields{Major: 23, Minor: 1, Patch: 1, Suffix: "-pre", Commit: "7d6be525f"},
+		},
+		"parsed from version": {
+			`{"version":"SPDK v22.09.1-pre git sha1 abc123"}`,
+			SpdkVersionFields{Major: 22, Minor: 9, Patch: 1, Suffix: "-pre", Commit: "abc123"},
+		},
+		"release without patch": {
+			`{"version":"SPDK v23.05"}`,
+			SpdkVersionFields{Major: 23, Minor: 5},
+		},
+		"unrecognised": {
+			`{"version":"unknown"}`,
+			SpdkVersionFields{},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ver SpdkVersion
+			if err := json.Unmarshal([]byte(tt.raw), &ver); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if ver.Fields != tt.want {
+				t.Error("fields: expected", tt.want, "received", ver.Fields)
+			}
+		})
+	}
+}
+
+func TestSpdk_SpdkVersionAtLeast(t *testing.T) {
+	ver := SpdkVersion{Fields: SpdkVersionFields{Major: 23, Minor: 1}}
+	tests := map[string]struct {
+		major, minor int
+		want         bool
+	}{
+		"same":        {23, 1, true},
+		"older minor": {23, 0, true},
+		"older major": {22, 9, true},
+		"newer minor": {23, 5, false},
+		"newer major": {24, 1, false},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ver.AtLeast(tt.major, tt.minor); got != tt.want {
+				t.Error("response: expected", tt.want, "received", got)
+			}
+		})
+	}
+}
+
+func TestSpdk_GetVersion(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "spdk.sock"), WithLogger(NopLogger{}))
+	ln := client.StartUnixListener()
+	defer ln.Close()
+	serve(ln, func(req RPCRequest) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v23.01","fields":{"major":23,"minor":1,"patch":0,"suffix":""}}}`, req.ID)
+	})
+	ver, err := client.GetVersion(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if ver.Version != "SPDK v23.01" || !ver.AtLeast(23, 1) {
+		t.Error("response: expected SPDK v23.01 received", ver)
+	}
+}
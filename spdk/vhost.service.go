@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+)
+
+// VhostService is interface to all vhost functions in spdk
+type VhostService interface {
+	CreateVhostBlkController(ctx context.Context, params VhostBlkParams) error
+	GetVhostControllers(ctx context.Context) ([]VhostController, error)
+	DeleteVhostController(ctx context.Context, ctrlr string) error
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// VhostServiceImpl implements VhostService interface
+type VhostServiceImpl struct {
+	client JSONRPC
+}
+
+// build time check that struct implements interface
+var _ VhostService = (*VhostServiceImpl)(nil)
+
+// NewVhostService is a constructor for VhostServiceImpl
+func NewVhostService(client JSONRPC) *VhostServiceImpl {
+	return &VhostServiceImpl{client}
+}
+
+// CreateVhostBlkController creates a vhost-user block controller on top of a bdev,
+// a controller name that is already in use is reported as ErrVhostControllerExists
+func (p *VhostServiceImpl) CreateVhostBlkController(ctx context.Context, params VhostBlkParams) error {
+	var result VhostCreateBlkControllerResult
+	err := p.client.Call(ctx, "vhost_create_blk_controller", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return wrapRPCError(err, ErrVhostControllerExists, EEXISTCode)
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not create vhost controller: %s", params.Ctrlr)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
+
+// GetVhostControllers lists all vhost controllers
+func (p *VhostServiceImpl) GetVhostControllers(ctx context.Context) ([]VhostController, error) {
+	var result []VhostController
+	err := p.client.Call(ctx, "vhost_get_controllers", nil, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteVhostController deletes a vhost controller of any type
+func (p *VhostServiceImpl) DeleteVhostController(ctx context.Context, ctrlr string) error {
+	params := VhostDeleteControllerParams{
+		Ctrlr: ctrlr,
+	}
+	var result VhostDeleteControllerResult
+	err := p.client.Call(ctx, "vhost_delete_controller", &params, &result)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return err
+	}
+	if !result {
+		msg := fmt.Sprintf("Could not delete vhost controller: %s", ctrlr)
+		log.Print(msg)
+		return ErrUnexpectedSpdkCallResult
+	}
+	return nil
+}
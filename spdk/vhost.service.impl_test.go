@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package spdk implements the spdk json-rpc protocol
+package spdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVhostService_CreateVhostBlkController(t *testing.T) {
+	tests := map[string]struct {
+		mock    *MockJSONRPC
+		wantErr error
+	}{
+		"created": {
+			NewMockJSONRPC().On("vhost_create_blk_controller", true),
+			nil,
+		},
+		"already exists": {
+			NewMockJSONRPC().OnError("vhost_create_blk_controller", &RPCError{Code: EEXISTCode, Message: "File exists"}),
+			ErrVhostControllerExists,
+		},
+		"unexpected result": {
+			NewMockJSONRPC().On("vhost_create_blk_controller", false),
+			ErrUnexpectedSpdkCallResult,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := VhostBlkParams{Ctrlr: "VhostBlk0", DevName: "Malloc0", Cpumask: "0x1", Readonly: true}
+			err := NewVhostService(tt.mock).CreateVhostBlkController(context.Background(), params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if args := tt.mock.Calls()[0].Args; !reflect.DeepEqual(args, &params) {
+				t.Error("args: expected", &params, "received", args)
+			}
+		})
+	}
+}
+
+func TestVhostService_GetVhostControllers(t *testing.T) {
+	mock := NewMockJSONRPC().On("vhost_get_controllers", `[{"ctrlr":"VhostBlk0","cpumask":"0x1","delay_base_us":0,
+		"iops_threshold":60000,"socket":"/var/tmp/VhostBlk0","backend_specific":{"block":{"readonly":false,"bdev":"Malloc0"}}}]`)
+	got, err := NewVhostService(mock).GetVhostControllers(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []VhostController{{
+		Ctrlr:         "VhostBlk0",
+		Cpumask:       "0x1",
+		IopsThreshold: 60000,
+		Socket:        "/var/tmp/VhostBlk0",
+	}}
+	want[0].BackendSpecific.Block.Bdev = "Malloc0"
+	if !reflect.DeepEqual(got, want) {
+		t.Error("response: expected", want, "received", got)
+	}
+}
+
+func TestVhostService_DeleteVhostController(t *testing.T) {
+	mock := NewMockJSONRPC().On("vhost_delete_controller", true)
+	if err := NewVhostService(mock).DeleteVhostController(context.Background(), "VhostBlk0"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := &VhostDeleteControllerParams{Ctrlr: "VhostBlk0"}
+	if args := mock.Calls()[0].Args; !reflect.DeepEqual(args, want) {
+		t.Error("args: expected", want, "received", args)
+	}
+}